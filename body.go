@@ -0,0 +1,114 @@
+package purefunccore
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ============================================================================
+// Request Body Limits and Content Types
+// ============================================================================
+
+// WithMaxBodySize limits request bodies to n bytes. Requests declaring a
+// larger Content-Length are rejected with 413 before the handler runs.
+// Otherwise reads past the limit fail with *http.MaxBytesError, and if the
+// handler has not written a response yet a 413 is sent for it.
+func (f HandlerFunc) WithMaxBodySize(n int64) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > n {
+			w.Header().Set("Connection", "close")
+			http.Error(w, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		sw := newStatusWriter(w)
+		body := &maxBodyReader{ReadCloser: http.MaxBytesReader(w, r.Body, n)}
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.Body = body
+		f(sw, r2)
+		if body.exceeded && !sw.wroteHeader {
+			http.Error(sw, "Request Entity Too Large", http.StatusRequestEntityTooLarge)
+		}
+	}
+}
+
+// maxBodyReader records whether the body limit was hit.
+type maxBodyReader struct {
+	io.ReadCloser
+	exceeded bool
+}
+
+func (m *maxBodyReader) Read(p []byte) (int, error) {
+	n, err := m.ReadCloser.Read(p)
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		m.exceeded = true
+	}
+	return n, err
+}
+
+// RequireContentType rejects requests carrying a body whose media type is
+// not one of types with 415 Unsupported Media Type, listing the accepted
+// types in the Accept response header. A type of the form "text/*" matches
+// any subtype. Requests without a body pass through.
+//
+// Example:
+//
+//	handler := HandlerFunc(createUser).RequireContentType("application/json")
+func (f HandlerFunc) RequireContentType(types ...string) HandlerFunc {
+	accept := strings.Join(types, ", ")
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength == 0 || r.Body == nil || r.Body == http.NoBody {
+			f(w, r)
+			return
+		}
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil || !matchMediaType(mediaType, types) {
+			w.Header().Set("Accept", accept)
+			http.Error(w, "Unsupported Media Type", http.StatusUnsupportedMediaType)
+			return
+		}
+		f(w, r)
+	}
+}
+
+func matchMediaType(mediaType string, types []string) bool {
+	for _, t := range types {
+		t = strings.ToLower(t)
+		if t == mediaType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// WithBodyTransform applies a ReadFunc combinator to the request body, so
+// that Take, Filter, Map or Tap can be used on incoming data. Closing the
+// body still closes the original.
+//
+// Example:
+//
+//	handler := HandlerFunc(upload).WithBodyTransform(func(r ReadFunc) ReadFunc {
+//	    return r.Take(1 << 20)
+//	})
+func (f HandlerFunc) WithBodyTransform(transform func(ReadFunc) ReadFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Body == nil || r.Body == http.NoBody {
+			f(w, r)
+			return
+		}
+		r2 := new(http.Request)
+		*r2 = *r
+		r2.Body = struct {
+			io.Reader
+			io.Closer
+		}{transform(ReadFunc(r.Body.Read)), r.Body}
+		f(w, r2)
+	}
+}
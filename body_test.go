@@ -0,0 +1,106 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Request Body Tests
+// ============================================================================
+
+func TestHandlerFunc_WithMaxBodySize(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			return
+		}
+		w.Write(data)
+	}).WithMaxBodySize(5)
+
+	tests := []struct {
+		name     string
+		body     string
+		chunked  bool
+		expected int
+	}{
+		{"within limit", "hello", false, http.StatusOK},
+		{"content length too large", "hello world", false, http.StatusRequestEntityTooLarge},
+		{"chunked too large", "hello world", true, http.StatusRequestEntityTooLarge},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		if tt.chunked {
+			req.ContentLength = -1
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, w.Code)
+		}
+	}
+}
+
+func TestHandlerFunc_RequireContentType(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}).
+		RequireContentType("application/json", "text/*")
+
+	tests := []struct {
+		contentType string
+		body        string
+		expected    int
+	}{
+		{"application/json; charset=utf-8", "{}", http.StatusOK},
+		{"TEXT/plain", "hi", http.StatusOK},
+		{"application/xml", "<a/>", http.StatusUnsupportedMediaType},
+		{"", "{}", http.StatusUnsupportedMediaType},
+		{"", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(tt.body))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("%q: expected %d, got %d", tt.contentType, tt.expected, w.Code)
+		}
+		if w.Code == http.StatusUnsupportedMediaType && w.Header().Get("Accept") != "application/json, text/*" {
+			t.Errorf("expected Accept header, got '%s'", w.Header().Get("Accept"))
+		}
+	}
+}
+
+func TestHandlerFunc_WithBodyTransform(t *testing.T) {
+	closed := false
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		r.Body.Close()
+		w.Write(data)
+	}).WithBodyTransform(func(r ReadFunc) ReadFunc {
+		return r.Filter(func(b byte) bool { return b != ' ' }).Take(6)
+	})
+
+	req := httptest.NewRequest("POST", "/", nil)
+	req.Body = ReadWriteCloser{
+		ReadFunc:  bytes.NewReader([]byte("a b c d e f g h")).Read,
+		CloseFunc: func() error { closed = true; return nil },
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Body.String() != "abcdef" {
+		t.Errorf("expected 'abcdef', got '%s'", w.Body.String())
+	}
+	if !closed {
+		t.Error("expected original body to be closed")
+	}
+}
@@ -0,0 +1,341 @@
+package purefunccore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Circuit Breaker
+// ============================================================================
+
+// ErrCircuitOpen is returned instead of calling a dependency whose circuit
+// breaker is open.
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState is the state of a CircuitBreaker.
+type CircuitState int
+
+const (
+	// CircuitClosed lets calls through while counting failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects calls until the cooldown has passed.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe calls through to test
+	// whether the dependency has recovered.
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+// CircuitBreakerConfig configures NewCircuitBreaker.
+type CircuitBreakerConfig struct {
+	// Window is the rolling period failure rates are measured over.
+	// Defaults to one minute, tracked in ten buckets.
+	Window time.Duration
+	// FailureRate opens the circuit once this share of calls in the window
+	// failed, provided at least MinRequests were made. Defaults to 0.5.
+	FailureRate float64
+	// MinRequests defaults to 10.
+	MinRequests int
+	// ConsecutiveFailures opens the circuit after this many failures in a
+	// row, regardless of the rate. Defaults to 5.
+	ConsecutiveFailures int
+	// Cooldown is how long the circuit stays open. Defaults to 30 seconds.
+	Cooldown time.Duration
+	// HalfOpenProbes is how many calls are let through while half-open;
+	// the circuit closes once all of them succeed. Defaults to 1.
+	HalfOpenProbes int
+	// IsFailure classifies non-nil errors. Defaults to every error.
+	// Calls ending in context.Canceled are never counted: they reflect the
+	// caller rather than the dependency.
+	IsFailure func(error) bool
+	// OnStateChange is called after every transition.
+	OnStateChange func(from, to CircuitState)
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+const circuitBuckets = 10
+
+// CircuitBreaker stops calls to a failing dependency so that failures do
+// not cascade, and lets them through again once it recovers.
+type CircuitBreaker struct {
+	cfg        CircuitBreakerConfig
+	bucketSize time.Duration
+
+	mu          sync.Mutex
+	state       CircuitState
+	generation  uint64
+	openedAt    time.Time
+	consecutive int
+	buckets     [circuitBuckets]circuitBucket
+	probes      int // half-open calls started
+	probeOK     int // half-open calls succeeded
+}
+
+type circuitBucket struct {
+	start             time.Time
+	success, failures int
+}
+
+// NewCircuitBreaker creates a closed circuit breaker.
+//
+// Example:
+//
+//	cb := NewCircuitBreaker(CircuitBreakerConfig{
+//	    Cooldown:      10 * time.Second,
+//	    OnStateChange: func(from, to CircuitState) { log.Printf("payments: %s -> %s", from, to) },
+//	})
+//	client := &http.Client{Transport: RoundTripperFunc(nil).Empty().WithCircuitBreaker(cb)}
+func NewCircuitBreaker(cfg CircuitBreakerConfig) *CircuitBreaker {
+	if cfg.Window <= 0 {
+		cfg.Window = time.Minute
+	}
+	if cfg.FailureRate <= 0 {
+		cfg.FailureRate = 0.5
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = 10
+	}
+	if cfg.ConsecutiveFailures <= 0 {
+		cfg.ConsecutiveFailures = 5
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	if cfg.IsFailure == nil {
+		cfg.IsFailure = func(error) bool { return true }
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &CircuitBreaker{cfg: cfg, bucketSize: cfg.Window / circuitBuckets}
+}
+
+// State returns the current state, moving from open to half-open once the
+// cooldown has passed.
+func (cb *CircuitBreaker) State() CircuitState {
+	cb.mu.Lock()
+	state, changed := cb.refresh(cb.cfg.Now())
+	cb.mu.Unlock()
+	cb.notify(changed)
+	return state
+}
+
+// circuitOutcome is how a finished call affects the breaker.
+type circuitOutcome int
+
+const (
+	circuitSuccess circuitOutcome = iota
+	circuitFailure
+	circuitIgnored // releases a half-open probe without a verdict
+)
+
+func (cb *CircuitBreaker) outcome(err error) circuitOutcome {
+	switch {
+	case err == nil:
+		return circuitSuccess
+	case errors.Is(err, context.Canceled):
+		return circuitIgnored
+	case cb.cfg.IsFailure(err):
+		return circuitFailure
+	}
+	return circuitSuccess
+}
+
+// Allow reserves a call. If the circuit rejects it, Allow returns
+// ErrCircuitOpen; otherwise the caller must report the call's error, or
+// nil on success, with done.
+func (cb *CircuitBreaker) Allow() (done func(err error), err error) {
+	report, err := cb.reserve()
+	if err != nil {
+		return nil, err
+	}
+	return func(err error) { report(cb.outcome(err)) }, nil
+}
+
+// reserve is Allow with the outcome already classified.
+func (cb *CircuitBreaker) reserve() (func(circuitOutcome), error) {
+	cb.mu.Lock()
+	now := cb.cfg.Now()
+	state, changed := cb.refresh(now)
+	switch {
+	case state == CircuitOpen,
+		state == CircuitHalfOpen && cb.probes >= cb.cfg.HalfOpenProbes:
+		cb.mu.Unlock()
+		cb.notify(changed)
+		return nil, ErrCircuitOpen
+	case state == CircuitHalfOpen:
+		cb.probes++
+	}
+	gen := cb.generation
+	cb.mu.Unlock()
+	cb.notify(changed)
+
+	var once sync.Once
+	return func(o circuitOutcome) {
+		once.Do(func() { cb.record(gen, o) })
+	}, nil
+}
+
+// transition describes a state change to report after unlocking.
+type transition struct{ from, to CircuitState }
+
+func (cb *CircuitBreaker) notify(changes []transition) {
+	if cb.cfg.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		cb.cfg.OnStateChange(c.from, c.to)
+	}
+}
+
+// refresh applies the cooldown. It must be called with mu held.
+func (cb *CircuitBreaker) refresh(now time.Time) (CircuitState, []transition) {
+	if cb.state == CircuitOpen && now.Sub(cb.openedAt) >= cb.cfg.Cooldown {
+		return cb.state, []transition{cb.setState(CircuitHalfOpen, now)}
+	}
+	return cb.state, nil
+}
+
+// setState moves to a new state and resets its bookkeeping.
+// It must be called with mu held.
+func (cb *CircuitBreaker) setState(to CircuitState, now time.Time) transition {
+	t := transition{cb.state, to}
+	cb.state = to
+	cb.generation++
+	cb.consecutive = 0
+	cb.probes, cb.probeOK = 0, 0
+	switch to {
+	case CircuitOpen:
+		cb.openedAt = now
+	case CircuitClosed:
+		cb.buckets = [circuitBuckets]circuitBucket{}
+	}
+	return t
+}
+
+func (cb *CircuitBreaker) record(gen uint64, o circuitOutcome) {
+	cb.mu.Lock()
+	now := cb.cfg.Now()
+	if gen != cb.generation {
+		// The call started before the last transition; its outcome no
+		// longer says anything about the current state.
+		cb.mu.Unlock()
+		return
+	}
+
+	var changes []transition
+	switch {
+	case o == circuitIgnored:
+		if cb.state == CircuitHalfOpen {
+			cb.probes--
+		}
+	case cb.state == CircuitHalfOpen:
+		if o == circuitFailure {
+			changes = append(changes, cb.setState(CircuitOpen, now))
+		} else if cb.probeOK++; cb.probeOK >= cb.cfg.HalfOpenProbes {
+			changes = append(changes, cb.setState(CircuitClosed, now))
+		}
+	case cb.state == CircuitClosed:
+		b := cb.bucket(now)
+		if o == circuitSuccess {
+			b.success++
+			cb.consecutive = 0
+			break
+		}
+		b.failures++
+		cb.consecutive++
+		total, failures := cb.counts(now)
+		if cb.consecutive >= cb.cfg.ConsecutiveFailures ||
+			(total >= cb.cfg.MinRequests && float64(failures)/float64(total) >= cb.cfg.FailureRate) {
+			changes = append(changes, cb.setState(CircuitOpen, now))
+		}
+	}
+	cb.mu.Unlock()
+	cb.notify(changes)
+}
+
+// bucket returns the bucket for now, recycling an expired one.
+func (cb *CircuitBreaker) bucket(now time.Time) *circuitBucket {
+	start := now.Truncate(cb.bucketSize)
+	b := &cb.buckets[(start.UnixNano()/int64(cb.bucketSize))%circuitBuckets]
+	if !b.start.Equal(start) {
+		*b = circuitBucket{start: start}
+	}
+	return b
+}
+
+func (cb *CircuitBreaker) counts(now time.Time) (total, failures int) {
+	for _, b := range cb.buckets {
+		if now.Sub(b.start) < cb.cfg.Window {
+			total += b.success + b.failures
+			failures += b.failures
+		}
+	}
+	return total, failures
+}
+
+// WithCircuitBreaker guards the transport with cb. Transport errors, 5xx
+// responses and panics count as failures; while the circuit is open
+// requests fail immediately with ErrCircuitOpen.
+func (f RoundTripperFunc) WithCircuitBreaker(cb *CircuitBreaker) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		report, err := cb.reserve()
+		if err != nil {
+			return nil, err
+		}
+		outcome := circuitFailure // unless f returns normally
+		defer func() { report(outcome) }()
+
+		resp, err := f(req)
+		outcome = cb.outcome(err)
+		if err == nil && resp.StatusCode >= 500 {
+			outcome = circuitFailure
+		}
+		return resp, err
+	}
+}
+
+// CircuitBreakerFunc guards any context-aware call with cb, so the same
+// breaker pattern applies to database queries, RPCs and queue producers.
+// A panic in fn counts as a failure and is propagated.
+//
+// Example:
+//
+//	getUser := CircuitBreakerFunc(cb, func(ctx context.Context) (*User, error) {
+//	    return repo.FindUser(ctx, id)
+//	})
+//	user, err := getUser(ctx)
+func CircuitBreakerFunc[T any](cb *CircuitBreaker, fn func(context.Context) (T, error)) func(context.Context) (T, error) {
+	return func(ctx context.Context) (T, error) {
+		report, err := cb.reserve()
+		if err != nil {
+			var zero T
+			return zero, err
+		}
+		outcome := circuitFailure // unless fn returns normally
+		defer func() { report(outcome) }()
+
+		v, err := fn(ctx)
+		outcome = cb.outcome(err)
+		return v, err
+	}
+}
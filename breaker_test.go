@@ -0,0 +1,250 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Circuit Breaker Tests
+// ============================================================================
+
+func TestCircuitBreaker_ConsecutiveFailures(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	var changes []string
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		ConsecutiveFailures: 3,
+		Cooldown:            10 * time.Second,
+		HalfOpenProbes:      2,
+		Now:                 clock.Now,
+		OnStateChange: func(from, to CircuitState) {
+			changes = append(changes, from.String()+"->"+to.String())
+		},
+	})
+
+	fail := errors.New("boom")
+	calls := 0
+	call := CircuitBreakerFunc(cb, func(ctx context.Context) (int, error) {
+		calls++
+		if calls <= 3 {
+			return 0, fail
+		}
+		return calls, nil
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := call(context.Background()); err != fail {
+			t.Fatalf("call %d: expected dependency error, got %v", i, err)
+		}
+	}
+	if cb.State() != CircuitOpen {
+		t.Fatalf("expected open, got %s", cb.State())
+	}
+	if _, err := call(context.Background()); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected open circuit to skip the call, got %d calls", calls)
+	}
+
+	clock.Advance(10 * time.Second)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected half-open after cooldown, got %s", cb.State())
+	}
+
+	// Only HalfOpenProbes calls are let through at once.
+	done1, err1 := cb.Allow()
+	done2, err2 := cb.Allow()
+	_, err3 := cb.Allow()
+	if err1 != nil || err2 != nil || !errors.Is(err3, ErrCircuitOpen) {
+		t.Fatalf("expected two probes then rejection, got %v %v %v", err1, err2, err3)
+	}
+	done1(nil)
+	done1(fail) // reported twice; ignored
+	if cb.State() != CircuitHalfOpen {
+		t.Errorf("expected half-open until all probes succeed, got %s", cb.State())
+	}
+	done2(nil)
+	if cb.State() != CircuitClosed {
+		t.Errorf("expected closed, got %s", cb.State())
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->closed"}
+	if len(changes) != len(want) {
+		t.Fatalf("expected transitions %v, got %v", want, changes)
+	}
+	for i := range want {
+		if changes[i] != want[i] {
+			t.Errorf("expected transition %s, got %s", want[i], changes[i])
+		}
+	}
+}
+
+func TestCircuitBreaker_HalfOpenFailureReopens(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1, Cooldown: time.Second, Now: clock.Now})
+
+	fail := errors.New("boom")
+	done, _ := cb.Allow()
+	done(fail)
+	clock.Advance(time.Second)
+	done, err := cb.Allow()
+	if err != nil {
+		t.Fatalf("expected probe to be allowed, got %v", err)
+	}
+	done(fail)
+	if cb.State() != CircuitOpen {
+		t.Errorf("expected failed probe to reopen, got %s", cb.State())
+	}
+	clock.Advance(500 * time.Millisecond)
+	if _, err := cb.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected cooldown to restart, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_FailureRate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{
+		Window:              10 * time.Second,
+		FailureRate:         0.5,
+		MinRequests:         4,
+		ConsecutiveFailures: 100,
+		Now:                 clock.Now,
+	})
+	report := func(failed bool) {
+		done, err := cb.Allow()
+		if err != nil {
+			t.Fatalf("unexpected rejection: %v", err)
+		}
+		if failed {
+			done(errors.New("boom"))
+		} else {
+			done(nil)
+		}
+	}
+
+	// Old failures age out of the window.
+	report(true)
+	report(true)
+	clock.Advance(15 * time.Second)
+	report(false)
+	report(true)
+	report(false)
+	if cb.State() != CircuitClosed {
+		t.Fatalf("expected closed below MinRequests, got %s", cb.State())
+	}
+	report(true) // 2 of 4
+	if cb.State() != CircuitOpen {
+		t.Errorf("expected open at 50%% failures, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_StaleOutcomeIgnored(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1, Cooldown: time.Second, Now: clock.Now})
+
+	slow, _ := cb.Allow()
+	done, _ := cb.Allow()
+	done(errors.New("boom"))
+	clock.Advance(time.Second)
+	probe, _ := cb.Allow()
+	slow(nil) // started while closed; must not close the circuit early
+	if cb.State() != CircuitHalfOpen {
+		t.Errorf("expected stale success to be ignored, got %s", cb.State())
+	}
+	probe(nil)
+	if cb.State() != CircuitClosed {
+		t.Errorf("expected closed, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_CanceledIsNotFailure(t *testing.T) {
+	cb := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1})
+	call := CircuitBreakerFunc(cb, func(ctx context.Context) (string, error) {
+		return "", ctx.Err()
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	call(ctx)
+	if cb.State() != CircuitClosed {
+		t.Errorf("expected cancellation not to trip the breaker, got %s", cb.State())
+	}
+}
+
+func TestCircuitBreaker_CanceledProbeReleased(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1, Cooldown: time.Second, Now: clock.Now})
+	done, _ := cb.Allow()
+	done(errors.New("boom"))
+	clock.Advance(time.Second)
+
+	probe, _ := cb.Allow()
+	probe(context.Canceled)
+	if cb.State() != CircuitHalfOpen {
+		t.Fatalf("expected canceled probe to leave the circuit half-open, got %s", cb.State())
+	}
+	if _, err := cb.Allow(); err != nil {
+		t.Errorf("expected canceled probe to release its slot, got %v", err)
+	}
+}
+
+func TestCircuitBreaker_PanicCountsAsFailure(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	cb := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 1, Cooldown: time.Second, Now: clock.Now})
+	call := CircuitBreakerFunc(cb, func(ctx context.Context) (int, error) {
+		panic("boom")
+	})
+	mustPanic := func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected panic to propagate")
+			}
+		}()
+		call(context.Background())
+	}
+
+	mustPanic()
+	if cb.State() != CircuitOpen {
+		t.Fatalf("expected panic to open the circuit, got %s", cb.State())
+	}
+	clock.Advance(time.Second)
+	mustPanic() // half-open probe
+	clock.Advance(time.Second)
+	if cb.State() != CircuitHalfOpen {
+		t.Errorf("expected panicking probe to reopen and cool down, got %s", cb.State())
+	}
+	if _, err := cb.Allow(); err != nil {
+		t.Errorf("expected a new probe to be allowed, got %v", err)
+	}
+}
+
+func TestRoundTripperFunc_WithCircuitBreaker(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cb := NewCircuitBreaker(CircuitBreakerConfig{ConsecutiveFailures: 2})
+	client := &http.Client{Transport: RoundTripperFunc(nil).Empty().WithCircuitBreaker(cb)}
+
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		resp.Body.Close()
+	}
+	if _, err := client.Get(server.URL); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("expected ErrCircuitOpen, got %v", err)
+	}
+	if hits != 2 {
+		t.Errorf("expected 2 requests to reach the server, got %d", hits)
+	}
+}
@@ -0,0 +1,409 @@
+package purefunccore
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Conditional Requests and Response Caching
+// ============================================================================
+
+// WithETag buffers the response, adds a strong ETag computed from the body
+// unless the handler set one (strong or weak), and answers GET and HEAD
+// requests carrying a matching If-None-Match, or an If-Modified-Since not
+// older than Last-Modified, with 304 Not Modified.
+// Responses that flush are streamed unchanged.
+func (f HandlerFunc) WithETag() HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rb := newResponseBuffer(w)
+		f(rb, r)
+		if rb.streaming {
+			return
+		}
+		if rb.status == 0 {
+			rb.status = http.StatusOK
+		}
+		if rb.status == http.StatusOK && rb.header.Get("ETag") == "" {
+			sum := sha256.Sum256(rb.body.Bytes())
+			rb.header.Set("ETag", `"`+base64.RawURLEncoding.EncodeToString(sum[:18])+`"`)
+		}
+		if (r.Method == http.MethodGet || r.Method == http.MethodHead) &&
+			rb.status == http.StatusOK && notModified(r, rb.header) {
+			writeNotModified(w, rb.header)
+			return
+		}
+		rb.writeTo(w)
+	}
+}
+
+// notModified evaluates If-None-Match, falling back to If-Modified-Since,
+// as described in RFC 9110 section 13.2.2.
+func notModified(r *http.Request, h http.Header) bool {
+	if inm := r.Header.Get("If-None-Match"); inm != "" {
+		etag := h.Get("ETag")
+		if etag == "" {
+			return false
+		}
+		for _, candidate := range strings.Split(inm, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || weakETagMatch(candidate, etag) {
+				return true
+			}
+		}
+		return false
+	}
+	ims, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	lastModified, err := http.ParseTime(h.Get("Last-Modified"))
+	return err == nil && !lastModified.After(ims)
+}
+
+func weakETagMatch(a, b string) bool {
+	return strings.TrimPrefix(a, "W/") == strings.TrimPrefix(b, "W/")
+}
+
+func writeNotModified(w http.ResponseWriter, h http.Header) {
+	for _, k := range []string{"Cache-Control", "Content-Location", "Date", "ETag", "Expires", "Last-Modified", "Vary"} {
+		if v := h.Values(k); len(v) > 0 {
+			w.Header()[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	w.WriteHeader(http.StatusNotModified)
+}
+
+// CachedResponse is a stored response variant.
+type CachedResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Vary holds the request header values the variant was selected by.
+	Vary map[string]string
+	// Stored is when the response was generated; Expires ends freshness
+	// and StaleUntil ends the stale-while-revalidate window.
+	Stored     time.Time
+	Expires    time.Time
+	StaleUntil time.Time
+}
+
+// CacheStore holds the response variants for a primary cache key.
+type CacheStore interface {
+	Get(key string) ([]*CachedResponse, bool)
+	Set(key string, variants []*CachedResponse)
+}
+
+// LRUCache is a bounded in-memory CacheStore that evicts the least
+// recently used keys once it holds more than its capacity.
+type LRUCache struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	items    map[string]*list.Element
+}
+
+type lruItem struct {
+	key      string
+	variants []*CachedResponse
+}
+
+// NewLRUCache creates a cache holding at most capacity keys.
+func NewLRUCache(capacity int) *LRUCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+	return &LRUCache{capacity: capacity, order: list.New(), items: make(map[string]*list.Element)}
+}
+
+// Get implements CacheStore.
+func (c *LRUCache) Get(key string) ([]*CachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruItem).variants, true
+}
+
+// Set implements CacheStore.
+func (c *LRUCache) Set(key string, variants []*CachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[key]; ok {
+		el.Value.(*lruItem).variants = variants
+		c.order.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.order.PushFront(&lruItem{key: key, variants: variants})
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.items, oldest.Value.(*lruItem).key)
+	}
+}
+
+// Len returns the number of cached keys.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// CachePolicy configures HandlerFunc.WithCache.
+type CachePolicy struct {
+	// DefaultTTL applies to responses without max-age, s-maxage or Expires.
+	// Zero leaves such responses uncached.
+	DefaultTTL time.Duration
+	// StaleWhileRevalidate applies when the response does not carry the
+	// stale-while-revalidate directive itself.
+	StaleWhileRevalidate time.Duration
+	// Key derives the primary cache key. Defaults to the request URL.
+	Key func(*http.Request) string
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// WithCache serves GET responses from store while they are fresh, honoring
+// Cache-Control (no-store, no-cache, private, max-age, s-maxage) and Vary.
+// Within the stale-while-revalidate window a stale response is served while
+// the handler refreshes the entry in the background. Requests carrying
+// Authorization and responses setting cookies are never cached.
+//
+// Example:
+//
+//	handler := HandlerFunc(listProducts).
+//	    WithCache(NewLRUCache(1000), CachePolicy{StaleWhileRevalidate: time.Minute})
+func (f HandlerFunc) WithCache(store CacheStore, policy CachePolicy) HandlerFunc {
+	if policy.Key == nil {
+		policy.Key = func(r *http.Request) string { return r.URL.String() }
+	}
+	if policy.Now == nil {
+		policy.Now = time.Now
+	}
+	var (
+		mu           sync.Mutex
+		revalidating = make(map[string]bool)
+	)
+
+	// fill runs the handler into a buffer and stores the result if cacheable.
+	fill := func(w http.ResponseWriter, r *http.Request, key string) *responseBuffer {
+		rb := newResponseBuffer(w)
+		f(rb, r)
+		if rb.streaming {
+			return nil
+		}
+		if entry := newCachedResponse(rb, r, policy); entry != nil {
+			mu.Lock()
+			variants, _ := store.Get(key)
+			store.Set(key, replaceVariant(variants, entry))
+			mu.Unlock()
+		}
+		return rb
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		reqCC := parseCacheControl(r.Header.Get("Cache-Control"))
+		if r.Method != http.MethodGet || r.Header.Get("Authorization") != "" || reqCC.has("no-store") {
+			f(w, r)
+			return
+		}
+		key := "GET " + policy.Key(r)
+		now := policy.Now()
+
+		if !reqCC.has("no-cache") && reqCC["max-age"] != "0" {
+			variants, _ := store.Get(key)
+			if entry := matchVariant(variants, r); entry != nil {
+				switch {
+				case now.Before(entry.Expires):
+					writeCached(w, r, entry, now, "HIT")
+					return
+				case now.Before(entry.StaleUntil):
+					mu.Lock()
+					start := !revalidating[key]
+					revalidating[key] = true
+					mu.Unlock()
+					if start {
+						bg := r.Clone(context.WithoutCancel(r.Context()))
+						go func() {
+							defer func() {
+								mu.Lock()
+								delete(revalidating, key)
+								mu.Unlock()
+							}()
+							fill(discardResponseWriter{header: make(http.Header)}, bg, key)
+						}()
+					}
+					writeCached(w, r, entry, now, "STALE")
+					return
+				}
+			}
+		}
+
+		rb := fill(w, r, key)
+		if rb == nil {
+			return
+		}
+		rb.header.Set("X-Cache", "MISS")
+		rb.writeTo(w)
+	}
+}
+
+func writeCached(w http.ResponseWriter, r *http.Request, entry *CachedResponse, now time.Time, status string) {
+	for k, v := range entry.Header {
+		w.Header()[k] = v
+	}
+	w.Header().Set("Age", strconv.Itoa(int(now.Sub(entry.Stored).Seconds())))
+	w.Header().Set("X-Cache", status)
+	if entry.Status == http.StatusOK && notModified(r, entry.Header) {
+		writeNotModified(w, entry.Header)
+		return
+	}
+	w.WriteHeader(entry.Status)
+	_, _ = w.Write(entry.Body)
+}
+
+func newCachedResponse(rb *responseBuffer, r *http.Request, policy CachePolicy) *CachedResponse {
+	switch rb.status {
+	case http.StatusOK, http.StatusNonAuthoritativeInfo, http.StatusNoContent,
+		http.StatusMovedPermanently, http.StatusNotFound, http.StatusGone:
+	default:
+		return nil
+	}
+	cc := parseCacheControl(rb.header.Get("Cache-Control"))
+	if cc.has("no-store") || cc.has("private") || cc.has("no-cache") ||
+		rb.header.Get("Set-Cookie") != "" || rb.header.Get("Vary") == "*" {
+		return nil
+	}
+
+	now := policy.Now()
+	ttl, ok := cc.seconds("s-maxage")
+	if !ok {
+		ttl, ok = cc.seconds("max-age")
+	}
+	if !ok {
+		if exp, err := http.ParseTime(rb.header.Get("Expires")); err == nil {
+			ttl, ok = exp.Sub(now), true
+		}
+	}
+	if !ok {
+		ttl = policy.DefaultTTL
+	}
+	if ttl <= 0 {
+		return nil
+	}
+	swr, ok := cc.seconds("stale-while-revalidate")
+	if !ok {
+		swr = policy.StaleWhileRevalidate
+	}
+
+	entry := &CachedResponse{
+		Status:     rb.status,
+		Header:     rb.header.Clone(),
+		Body:       append([]byte(nil), rb.body.Bytes()...),
+		Vary:       make(map[string]string),
+		Stored:     now,
+		Expires:    now.Add(ttl),
+		StaleUntil: now.Add(ttl + swr),
+	}
+	for _, name := range varyNames(rb.header) {
+		entry.Vary[name] = r.Header.Get(name)
+	}
+	return entry
+}
+
+func varyNames(h http.Header) []string {
+	var names []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	return names
+}
+
+func matchVariant(variants []*CachedResponse, r *http.Request) *CachedResponse {
+	for _, v := range variants {
+		if varyMatches(v, r.Header) {
+			return v
+		}
+	}
+	return nil
+}
+
+func varyMatches(v *CachedResponse, h http.Header) bool {
+	for name, value := range v.Vary {
+		if h.Get(name) != value {
+			return false
+		}
+	}
+	return true
+}
+
+func replaceVariant(variants []*CachedResponse, entry *CachedResponse) []*CachedResponse {
+	out := make([]*CachedResponse, 0, len(variants)+1)
+	out = append(out, entry)
+	for _, v := range variants {
+		same := len(v.Vary) == len(entry.Vary)
+		for name, value := range entry.Vary {
+			if v.Vary[name] != value {
+				same = false
+			}
+		}
+		if !same {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// cacheControl holds parsed Cache-Control directives.
+type cacheControl map[string]string
+
+func parseCacheControl(s string) cacheControl {
+	cc := make(cacheControl)
+	for _, part := range strings.Split(s, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return cc
+}
+
+func (cc cacheControl) has(name string) bool {
+	_, ok := cc[name]
+	return ok
+}
+
+func (cc cacheControl) seconds(name string) (time.Duration, bool) {
+	v, ok := cc[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// discardResponseWriter is the target of background revalidation.
+type discardResponseWriter struct {
+	header http.Header
+}
+
+func (d discardResponseWriter) Header() http.Header         { return d.header }
+func (d discardResponseWriter) Write(p []byte) (int, error) { return len(p), nil }
+func (d discardResponseWriter) WriteHeader(int)             {}
@@ -0,0 +1,218 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Conditional Request and Cache Tests
+// ============================================================================
+
+func TestHandlerFunc_WithETag(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("X-Internal", "yes")
+		io.WriteString(w, "hello")
+	}).WithETag()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	etag := w.Header().Get("ETag")
+	if etag == "" || w.Body.String() != "hello" {
+		t.Fatalf("expected body with ETag, got %q (ETag %q)", w.Body.String(), etag)
+	}
+
+	tests := []struct {
+		inm  string
+		want int
+	}{
+		{etag, http.StatusNotModified},
+		{"W/" + etag, http.StatusNotModified},
+		{`"other", ` + etag, http.StatusNotModified},
+		{"*", http.StatusNotModified},
+		{`"other"`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-None-Match", tt.inm)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("If-None-Match %s: expected %d, got %d", tt.inm, tt.want, w.Code)
+		}
+		if tt.want == http.StatusNotModified {
+			if w.Body.Len() != 0 || w.Header().Get("X-Internal") != "" {
+				t.Errorf("If-None-Match %s: expected bare 304", tt.inm)
+			}
+			if w.Header().Get("Cache-Control") != "max-age=60" {
+				t.Errorf("If-None-Match %s: expected Cache-Control on 304", tt.inm)
+			}
+		}
+	}
+}
+
+func TestHandlerFunc_WithETag_IfModifiedSince(t *testing.T) {
+	modified := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `W/"v1"`)
+		w.Header().Set("Last-Modified", modified.Format(http.TimeFormat))
+		io.WriteString(w, "hello")
+	}).WithETag()
+
+	tests := []struct {
+		since time.Time
+		want  int
+	}{
+		{modified, http.StatusNotModified},
+		{modified.Add(time.Hour), http.StatusNotModified},
+		{modified.Add(-time.Hour), http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("If-Modified-Since", tt.since.Format(http.TimeFormat))
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.want {
+			t.Errorf("If-Modified-Since %v: expected %d, got %d", tt.since, tt.want, w.Code)
+		}
+		if w.Header().Get("ETag") != `W/"v1"` {
+			t.Errorf("expected handler ETag to be kept, got '%s'", w.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestHandlerFunc_WithCache(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	var calls int32
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		switch r.URL.Path {
+		case "/private":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		case "/vary":
+			w.Header().Set("Cache-Control", "max-age=60")
+			w.Header().Set("Vary", "Accept-Language")
+		default:
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		io.WriteString(w, string(rune('0'+n)))
+	}).WithCache(NewLRUCache(10), CachePolicy{Now: clock.Now})
+
+	get := func(path string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", path, nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	if w := get("/"); w.Header().Get("X-Cache") != "MISS" || w.Body.String() != "1" {
+		t.Errorf("expected MISS with body 1, got %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	clock.Advance(10 * time.Second)
+	w := get("/")
+	if w.Header().Get("X-Cache") != "HIT" || w.Body.String() != "1" {
+		t.Errorf("expected HIT with body 1, got %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	if w.Header().Get("Age") != "10" {
+		t.Errorf("expected Age 10, got '%s'", w.Header().Get("Age"))
+	}
+	if w := get("/", "Cache-Control", "no-cache"); w.Body.String() != "2" {
+		t.Errorf("expected no-cache to revalidate, got %q", w.Body.String())
+	}
+	if w := get("/", "Authorization", "Bearer x"); w.Header().Get("X-Cache") != "" {
+		t.Error("expected requests with Authorization to bypass the cache")
+	}
+
+	get("/private")
+	if w := get("/private"); w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("expected private response not to be cached, got %s", w.Header().Get("X-Cache"))
+	}
+
+	en := get("/vary", "Accept-Language", "en").Body.String()
+	de := get("/vary", "Accept-Language", "de").Body.String()
+	if en == de {
+		t.Error("expected separate variants per Accept-Language")
+	}
+	if got := get("/vary", "Accept-Language", "en").Body.String(); got != en {
+		t.Errorf("expected cached en variant %q, got %q", en, got)
+	}
+
+	clock.Advance(time.Minute)
+	if w := get("/"); w.Header().Get("X-Cache") != "MISS" {
+		t.Errorf("expected expired entry to miss, got %s", w.Header().Get("X-Cache"))
+	}
+}
+
+func TestHandlerFunc_WithCache_StaleWhileRevalidate(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	var calls int32
+	refreshed := make(chan struct{}, 1)
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Cache-Control", "max-age=10, stale-while-revalidate=30")
+		io.WriteString(w, string(rune('0'+n)))
+		if n > 1 {
+			refreshed <- struct{}{}
+		}
+	}).WithCache(NewLRUCache(10), CachePolicy{Now: clock.Now})
+
+	get := func() *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		return w
+	}
+
+	get()
+	clock.Advance(20 * time.Second)
+	w := get()
+	if w.Header().Get("X-Cache") != "STALE" || w.Body.String() != "1" {
+		t.Fatalf("expected stale body 1, got %s %q", w.Header().Get("X-Cache"), w.Body.String())
+	}
+	select {
+	case <-refreshed:
+	case <-time.After(time.Second):
+		t.Fatal("expected background revalidation")
+	}
+
+	// The refreshed entry is stored once the handler returns.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if w := get(); w.Header().Get("X-Cache") == "HIT" {
+			if w.Body.String() != "2" {
+				t.Errorf("expected refreshed body 2, got %q", w.Body.String())
+			}
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Error("expected refreshed entry to be served")
+}
+
+func TestLRUCache_Evicts(t *testing.T) {
+	c := NewLRUCache(2)
+	c.Set("a", nil)
+	c.Set("b", nil)
+	c.Get("a")
+	c.Set("c", nil)
+
+	if _, ok := c.Get("b"); ok {
+		t.Error("expected least recently used key to be evicted")
+	}
+	if _, ok := c.Get("a"); !ok {
+		t.Error("expected recently used key to be kept")
+	}
+	if c.Len() != 2 {
+		t.Errorf("expected 2 entries, got %d", c.Len())
+	}
+}
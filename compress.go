@@ -0,0 +1,234 @@
+package purefunccore
+
+import (
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Response Compression
+// ============================================================================
+
+// CompressionOptions configures HandlerFunc.WithCompression.
+type CompressionOptions struct {
+	// Level is the gzip/zlib compression level. Defaults to gzip.DefaultCompression.
+	Level int
+	// MinSize is the smallest body worth compressing. Defaults to 1024 bytes.
+	MinSize int
+	// Skip reports content types that must not be compressed.
+	// Defaults to IsCompressedContentType.
+	Skip func(contentType string) bool
+}
+
+// WithCompression compresses responses with gzip or deflate according to the
+// request's Accept-Encoding. Small bodies, HEAD requests, partial content
+// and already-compressed content types are sent unchanged. Flushing is
+// supported, so streaming responses are compressed incrementally.
+// It panics if Level is outside the range accepted by compress/gzip.
+//
+// Example:
+//
+//	handler := HandlerFunc(listProducts).
+//	    WithCompression(CompressionOptions{MinSize: 512}).
+//	    WithLogging(log.Println)
+func (f HandlerFunc) WithCompression(opts CompressionOptions) HandlerFunc {
+	if opts.Level == 0 {
+		opts.Level = gzip.DefaultCompression
+	}
+	if opts.Level < gzip.HuffmanOnly || opts.Level > gzip.BestCompression {
+		panic(fmt.Sprintf("purefunccore: invalid compression level %d", opts.Level))
+	}
+	if opts.MinSize <= 0 {
+		opts.MinSize = 1024
+	}
+	if opts.Skip == nil {
+		opts.Skip = IsCompressedContentType
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+		if encoding == "" || r.Method == http.MethodHead {
+			f(w, r)
+			return
+		}
+		cw := &compressWriter{sw: newStatusWriter(w), encoding: encoding, opts: opts}
+		defer cw.close()
+		f(cw, r)
+	}
+}
+
+// IsCompressedContentType reports whether compressing the content type is
+// unlikely to help (images, audio, video and archives).
+func IsCompressedContentType(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mediaType == "image/svg+xml":
+		return false
+	case strings.HasPrefix(mediaType, "image/"),
+		strings.HasPrefix(mediaType, "video/"),
+		strings.HasPrefix(mediaType, "audio/"):
+		return true
+	}
+	switch mediaType {
+	case "application/zip", "application/gzip", "application/x-gzip",
+		"application/zstd", "application/x-brotli", "application/x-bzip2",
+		"application/x-7z-compressed", "application/x-rar-compressed",
+		"font/woff", "font/woff2":
+		return true
+	}
+	return false
+}
+
+// negotiateEncoding picks gzip or deflate from an Accept-Encoding header.
+func negotiateEncoding(accept string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(accept, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		coding = strings.ToLower(strings.TrimSpace(coding))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		if coding == "*" {
+			coding = "gzip"
+		}
+		if (coding != "gzip" && coding != "deflate") || q <= 0 {
+			continue
+		}
+		// Prefer gzip on ties; it is the more widely supported format.
+		if q > bestQ || (q == bestQ && coding == "gzip") {
+			best, bestQ = coding, q
+		}
+	}
+	return best
+}
+
+type flushWriteCloser interface {
+	io.WriteCloser
+	Flush() error
+}
+
+// compressWriter buffers the start of the body until it can decide whether
+// compression is worthwhile, then streams through an encoder.
+type compressWriter struct {
+	sw       *statusWriter
+	encoding string
+	opts     CompressionOptions
+	status   int
+	buf      []byte
+	decided  bool
+	enc      flushWriteCloser
+}
+
+func (cw *compressWriter) Header() http.Header {
+	return cw.sw.Header()
+}
+
+func (cw *compressWriter) WriteHeader(code int) {
+	if cw.decided || cw.status != 0 {
+		return
+	}
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		cw.sw.WriteHeader(code)
+		return
+	}
+	cw.status = code
+}
+
+func (cw *compressWriter) Write(p []byte) (int, error) {
+	if !cw.decided {
+		cw.buf = append(cw.buf, p...)
+		if len(cw.buf) < cw.opts.MinSize {
+			return len(p), nil
+		}
+		if err := cw.decide(false); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if cw.enc != nil {
+		return cw.enc.Write(p)
+	}
+	return cw.sw.Write(p)
+}
+
+func (cw *compressWriter) Flush() {
+	if !cw.decided {
+		if err := cw.decide(true); err != nil {
+			return
+		}
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Flush()
+	}
+	cw.sw.Flush()
+}
+
+func (cw *compressWriter) Unwrap() http.ResponseWriter {
+	return cw.sw
+}
+
+// decide writes the header and buffered body, choosing whether to compress.
+// A streaming flush compresses regardless of the size seen so far.
+func (cw *compressWriter) decide(streaming bool) error {
+	cw.decided = true
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	h := cw.sw.Header()
+	if _, ok := h["Content-Type"]; !ok && len(cw.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(cw.buf))
+	}
+
+	compress := (streaming || len(cw.buf) >= cw.opts.MinSize) &&
+		cw.status != http.StatusNoContent &&
+		cw.status != http.StatusNotModified &&
+		cw.status != http.StatusPartialContent &&
+		h.Get("Content-Encoding") == "" &&
+		h.Get("Content-Range") == "" &&
+		!cw.opts.Skip(h.Get("Content-Type"))
+
+	if compress {
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", cw.encoding)
+		if cw.encoding == "gzip" {
+			cw.enc, _ = gzip.NewWriterLevel(cw.sw, cw.opts.Level)
+		} else {
+			cw.enc, _ = zlib.NewWriterLevel(cw.sw, cw.opts.Level)
+		}
+	}
+	cw.sw.WriteHeader(cw.status)
+
+	buf := cw.buf
+	cw.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	if cw.enc != nil {
+		_, err := cw.enc.Write(buf)
+		return err
+	}
+	_, err := cw.sw.Write(buf)
+	return err
+}
+
+func (cw *compressWriter) close() {
+	if !cw.decided {
+		if cw.status == 0 && len(cw.buf) == 0 {
+			// Nothing was written; let net/http send its default response.
+			return
+		}
+		_ = cw.decide(false)
+	}
+	if cw.enc != nil {
+		_ = cw.enc.Close()
+	}
+}
@@ -0,0 +1,118 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"bufio"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Compression Tests
+// ============================================================================
+
+func TestHandlerFunc_WithCompression(t *testing.T) {
+	large := strings.Repeat("hello compression ", 200)
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Length", "3600")
+			io.WriteString(w, large)
+		case "/small":
+			io.WriteString(w, "tiny")
+		case "/image":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, large)
+		}
+	}).WithCompression(CompressionOptions{}).WithLogging(func(string) {}).WithTimeout(time.Second)
+
+	tests := []struct {
+		path, accept, encoding string
+	}{
+		{"/large", "gzip, deflate", "gzip"},
+		{"/large", "deflate", "deflate"},
+		{"/large", "gzip;q=0.5, deflate", "deflate"},
+		{"/large", "br", ""},
+		{"/small", "gzip", ""},
+		{"/image", "gzip", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", tt.path, nil)
+		req.Header.Set("Accept-Encoding", tt.accept)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+			t.Errorf("%s (%s): expected encoding '%s', got '%s'", tt.path, tt.accept, tt.encoding, got)
+			continue
+		}
+		if w.Header().Get("Vary") != "Accept-Encoding" {
+			t.Errorf("%s: expected Vary: Accept-Encoding", tt.path)
+		}
+
+		var body io.Reader = w.Body
+		switch tt.encoding {
+		case "gzip":
+			body, _ = gzip.NewReader(w.Body)
+		case "deflate":
+			body, _ = zlib.NewReader(w.Body)
+		}
+		data, _ := io.ReadAll(body)
+		if tt.encoding != "" {
+			if w.Header().Get("Content-Length") != "" {
+				t.Errorf("%s: expected Content-Length to be removed", tt.path)
+			}
+			if string(data) != large {
+				t.Errorf("%s: decompressed body mismatch", tt.path)
+			}
+			if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+				t.Errorf("%s: expected sniffed text/plain, got '%s'", tt.path, ct)
+			}
+		}
+	}
+}
+
+func TestHandlerFunc_WithCompression_Flush(t *testing.T) {
+	next := make(chan struct{})
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: one\n\n")
+		http.NewResponseController(w).Flush()
+		<-next
+		io.WriteString(w, "data: two\n\n")
+	}).WithCompression(CompressionOptions{})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if !resp.Uncompressed {
+		t.Fatal("expected transport to receive a gzip response")
+	}
+
+	// The first event must arrive before the handler finishes.
+	line, _ := bufio.NewReader(resp.Body).ReadString('\n')
+	if line != "data: one\n" {
+		t.Errorf("expected first event to be flushed, got %q", line)
+	}
+	close(next)
+}
+
+func TestHandlerFunc_WithCompression_InvalidLevel(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for out-of-range level")
+		}
+	}()
+	HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}).WithCompression(CompressionOptions{Level: 42})
+}
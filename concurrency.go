@@ -0,0 +1,227 @@
+package purefunccore
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Concurrency Limiting
+// ============================================================================
+
+// ConcurrencyAlgorithm selects how a ConcurrencyLimiter adjusts its limit.
+type ConcurrencyAlgorithm int
+
+const (
+	// FixedLimit never changes the limit.
+	FixedLimit ConcurrencyAlgorithm = iota
+	// AIMDLimit grows the limit by one while latency stays under
+	// LatencyThreshold and shrinks it multiplicatively when it is exceeded.
+	AIMDLimit
+	// GradientLimit compares recent latency with the long-term average and
+	// shrinks the limit as queueing delay builds up.
+	GradientLimit
+)
+
+// ConcurrencyConfig configures a ConcurrencyLimiter.
+type ConcurrencyConfig struct {
+	// Limit is the fixed limit, or the initial limit in adaptive modes.
+	Limit int
+	// MinLimit and MaxLimit bound adaptive limits. Default to 1 and 10*Limit.
+	MinLimit, MaxLimit int
+	// MaxQueue is the number of requests allowed to wait for a slot.
+	MaxQueue int
+	// QueueTimeout is how long a queued request waits before being shed.
+	// Zero disables queueing.
+	QueueTimeout time.Duration
+	Algorithm    ConcurrencyAlgorithm
+	// LatencyThreshold marks a request as overloaded in AIMDLimit mode.
+	// Defaults to one second.
+	LatencyThreshold time.Duration
+	// Backoff is the AIMD multiplicative decrease factor. Defaults to 0.9.
+	Backoff float64
+	// RetryAfter is advertised on 503 responses. Defaults to one second.
+	RetryAfter time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// ConcurrencyLimiter caps the number of requests executing at once.
+// It is safe for concurrent use and may be shared between routes.
+type ConcurrencyLimiter struct {
+	cfg      ConcurrencyConfig
+	mu       sync.Mutex
+	limit    float64
+	inFlight int
+	waiters  []chan struct{}
+	longRTT  float64 // gradient: exponentially smoothed latency, in seconds
+}
+
+// NewConcurrencyLimiter creates a limiter from cfg.
+func NewConcurrencyLimiter(cfg ConcurrencyConfig) *ConcurrencyLimiter {
+	if cfg.Limit < 1 {
+		cfg.Limit = 1
+	}
+	if cfg.MinLimit < 1 {
+		cfg.MinLimit = 1
+	}
+	if cfg.MaxLimit < cfg.Limit {
+		cfg.MaxLimit = 10 * cfg.Limit
+	}
+	if cfg.LatencyThreshold <= 0 {
+		cfg.LatencyThreshold = time.Second
+	}
+	if cfg.Backoff <= 0 || cfg.Backoff >= 1 {
+		cfg.Backoff = 0.9
+	}
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = time.Second
+	}
+	if cfg.Now == nil {
+		cfg.Now = time.Now
+	}
+	return &ConcurrencyLimiter{cfg: cfg, limit: float64(cfg.Limit)}
+}
+
+// Limit returns the current limit.
+func (l *ConcurrencyLimiter) Limit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return int(l.limit)
+}
+
+// InFlight returns the number of requests currently holding a slot.
+func (l *ConcurrencyLimiter) InFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.inFlight
+}
+
+// Acquire obtains a slot, waiting in the queue if allowed.
+// It returns a release function, or false if the request should be shed.
+func (l *ConcurrencyLimiter) Acquire(ctx context.Context) (release func(), ok bool) {
+	l.mu.Lock()
+	if l.inFlight < int(l.limit) {
+		l.inFlight++
+		l.mu.Unlock()
+		return l.releaser(), true
+	}
+	if l.cfg.QueueTimeout <= 0 || len(l.waiters) >= l.cfg.MaxQueue {
+		l.mu.Unlock()
+		return nil, false
+	}
+	ch := make(chan struct{})
+	l.waiters = append(l.waiters, ch)
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.cfg.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case <-ch:
+		return l.releaser(), true
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i := slices.Index(l.waiters, ch); i >= 0 {
+		l.waiters = slices.Delete(l.waiters, i, i+1)
+		return nil, false
+	}
+	// The slot was handed over while we were giving up.
+	return l.releaser(), true
+}
+
+func (l *ConcurrencyLimiter) releaser() func() {
+	start := l.cfg.Now()
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.release(l.cfg.Now().Sub(start)) })
+	}
+}
+
+func (l *ConcurrencyLimiter) release(latency time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.adjust(latency)
+	if len(l.waiters) > 0 && l.inFlight <= int(l.limit) {
+		close(l.waiters[0])
+		l.waiters = l.waiters[1:]
+		return
+	}
+	l.inFlight--
+}
+
+func (l *ConcurrencyLimiter) adjust(latency time.Duration) {
+	utilized := l.inFlight*2 >= int(l.limit)
+	switch l.cfg.Algorithm {
+	case AIMDLimit:
+		if latency > l.cfg.LatencyThreshold {
+			l.limit *= l.cfg.Backoff
+		} else if utilized {
+			l.limit++
+		}
+	case GradientLimit:
+		rtt := latency.Seconds()
+		if l.longRTT == 0 {
+			l.longRTT = rtt
+		}
+		l.longRTT = l.longRTT*0.95 + rtt*0.05
+		if rtt <= 0 {
+			return
+		}
+		// Tolerate latency up to 1.5x the long-term average before shrinking.
+		gradient := math.Max(0.5, math.Min(1, 1.5*l.longRTT/rtt))
+		next := l.limit*gradient + math.Sqrt(l.limit)
+		if next > l.limit && !utilized {
+			return
+		}
+		l.limit = l.limit*0.8 + next*0.2
+	default:
+		return
+	}
+	l.limit = math.Max(float64(l.cfg.MinLimit), math.Min(float64(l.cfg.MaxLimit), l.limit))
+}
+
+// WithConcurrencyLimit sheds requests with 503 and Retry-After when the
+// limiter is saturated and its wait queue is full or times out.
+//
+// Example:
+//
+//	limiter := NewConcurrencyLimiter(ConcurrencyConfig{
+//	    Limit:        20,
+//	    MaxQueue:     50,
+//	    QueueTimeout: 100 * time.Millisecond,
+//	    Algorithm:    GradientLimit,
+//	})
+//	handler := HandlerFunc(report).WithConcurrencyLimit(limiter)
+func (f HandlerFunc) WithConcurrencyLimit(l *ConcurrencyLimiter) HandlerFunc {
+	retryAfter := ceilSeconds(l.cfg.RetryAfter)
+	return func(w http.ResponseWriter, r *http.Request) {
+		release, ok := l.Acquire(r.Context())
+		if !ok {
+			w.Header().Set("Retry-After", retryAfter)
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		defer release()
+		f(w, r)
+	}
+}
+
+// WithMaxInFlight allows at most n concurrent executions of the handler.
+// When queueTimeout is positive, up to n further requests wait that long for
+// a slot before being shed with 503.
+func (f HandlerFunc) WithMaxInFlight(n int, queueTimeout time.Duration) HandlerFunc {
+	return f.WithConcurrencyLimit(NewConcurrencyLimiter(ConcurrencyConfig{
+		Limit:        n,
+		MaxQueue:     n,
+		QueueTimeout: queueTimeout,
+	}))
+}
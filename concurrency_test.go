@@ -0,0 +1,156 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Concurrency Limit Tests
+// ============================================================================
+
+func TestHandlerFunc_WithMaxInFlight_Sheds(t *testing.T) {
+	started := make(chan struct{})
+	unblock := make(chan struct{})
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}).WithMaxInFlight(1, 0)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+	<-started
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "1" {
+		t.Errorf("expected Retry-After 1, got '%s'", w.Header().Get("Retry-After"))
+	}
+
+	close(unblock)
+	wg.Wait()
+}
+
+func TestHandlerFunc_WithMaxInFlight_Queues(t *testing.T) {
+	started := make(chan struct{}, 2)
+	unblock := make(chan struct{})
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started <- struct{}{}
+		<-unblock
+		w.WriteHeader(http.StatusOK)
+	}).WithMaxInFlight(1, time.Second)
+
+	codes := make(chan int, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+			codes <- w.Code
+		}()
+	}
+	<-started
+	close(unblock)
+
+	for i := 0; i < 2; i++ {
+		if code := <-codes; code != http.StatusOK {
+			t.Errorf("expected queued request to succeed, got %d", code)
+		}
+	}
+}
+
+func TestConcurrencyLimiter_QueueTimeout(t *testing.T) {
+	l := NewConcurrencyLimiter(ConcurrencyConfig{Limit: 1, MaxQueue: 1, QueueTimeout: 10 * time.Millisecond})
+
+	release, ok := l.Acquire(context.Background())
+	if !ok {
+		t.Fatal("expected first acquire to succeed")
+	}
+	defer release()
+
+	if _, ok := l.Acquire(context.Background()); ok {
+		t.Error("expected queued acquire to time out")
+	}
+	if l.InFlight() != 1 {
+		t.Errorf("expected 1 in flight, got %d", l.InFlight())
+	}
+}
+
+func TestConcurrencyLimiter_AIMD(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	l := NewConcurrencyLimiter(ConcurrencyConfig{
+		Limit:            10,
+		Algorithm:        AIMDLimit,
+		LatencyThreshold: 100 * time.Millisecond,
+		Backoff:          0.5,
+		Now:              clock.Now,
+	})
+
+	release, _ := l.Acquire(context.Background())
+	clock.Advance(time.Second)
+	release()
+	if l.Limit() != 5 {
+		t.Errorf("expected limit to halve under overload, got %d", l.Limit())
+	}
+
+	var releases []func()
+	for i := 0; i < 5; i++ {
+		r, _ := l.Acquire(context.Background())
+		releases = append(releases, r)
+	}
+	clock.Advance(10 * time.Millisecond)
+	releases[0]()
+	if l.Limit() != 6 {
+		t.Errorf("expected limit to grow when healthy and utilized, got %d", l.Limit())
+	}
+	for _, r := range releases[1:] {
+		r()
+	}
+}
+
+func TestConcurrencyLimiter_AIMDDefaultThreshold(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	l := NewConcurrencyLimiter(ConcurrencyConfig{Limit: 2, Algorithm: AIMDLimit, Now: clock.Now})
+
+	for i := 0; i < 5; i++ {
+		release, _ := l.Acquire(context.Background())
+		clock.Advance(10 * time.Millisecond)
+		release()
+	}
+	if l.Limit() < 2 {
+		t.Errorf("expected fast requests not to shrink the limit, got %d", l.Limit())
+	}
+}
+
+func TestConcurrencyLimiter_Gradient(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	l := NewConcurrencyLimiter(ConcurrencyConfig{Limit: 20, Algorithm: GradientLimit, Now: clock.Now})
+
+	sample := func(latency time.Duration) {
+		release, _ := l.Acquire(context.Background())
+		clock.Advance(latency)
+		release()
+	}
+	for i := 0; i < 20; i++ {
+		sample(10 * time.Millisecond)
+	}
+	before := l.Limit()
+	for i := 0; i < 5; i++ {
+		sample(200 * time.Millisecond)
+	}
+	if after := l.Limit(); after >= before {
+		t.Errorf("expected limit to shrink as latency rises, got %d -> %d", before, after)
+	}
+}
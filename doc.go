@@ -67,6 +67,20 @@ IO Package:
 HTTP Package:
   - HandlerFunc: http.Handler with middleware composition
   - RoundTripperFunc: http.RoundTripper for custom HTTP clients
+  - Middleware: Reusable HandlerFunc decorators with Chain, When and Unless
+  - JSONHandler: Typed JSON endpoints with validation and problem+json errors
+  - Router: http.ServeMux pattern routing with groups and named routes
+  - JWTConfig, JWKS: Standard-library JWT verification for WithJWT
+  - LRUCache, CachePolicy: In-memory response caching for WithCache
+  - SecurityPolicy, CSRFOptions: Security headers and CSRF protection
+  - IdempotencyStore: Idempotency-Key response replay for WithIdempotency
+  - SSEHandler, EventStream: Server-Sent Events with heartbeats and resume
+  - Server: Graceful shutdown with drain, readiness and shutdown hooks
+  - HealthChecker: Concurrent health checks with /livez and /readyz reports
+  - DumpOptions: Sampled HAR or raw capture of requests and responses
+  - MetricsRegistry: Prometheus text metrics recorded by WithMetrics
+  - CircuitBreaker: Closed/open/half-open protection for failing dependencies
+  - RaceTransports: Hedged and raced outbound requests for tail latency
 
 Context Package:
   - ContextFunc: Custom context implementations
@@ -0,0 +1,331 @@
+package purefunccore
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+)
+
+// ============================================================================
+// Request and Response Dumps
+// ============================================================================
+
+// DumpFormat selects how WithDump renders an exchange.
+type DumpFormat int
+
+const (
+	// DumpRaw writes HTTP/1.1 wire format as produced by httputil.DumpRequest.
+	DumpRaw DumpFormat = iota
+	// DumpHAR writes one HAR 1.2 entry as a JSON line. Wrap the entries in
+	// {"log":{"version":"1.2","entries":[...]}} to open them in browser tools.
+	DumpHAR
+)
+
+// DumpOptions configures HandlerFunc.WithDump.
+type DumpOptions struct {
+	// Sink receives one record per sampled exchange.
+	Sink   WriteFunc
+	Format DumpFormat
+	// MaxBodySize caps how much of each body is captured. Defaults to 64 KiB.
+	MaxBodySize int
+	// RedactHeaders lists headers whose values are replaced. Defaults to
+	// Authorization, Proxy-Authorization, Cookie, Set-Cookie and X-Api-Key.
+	RedactHeaders []string
+	// RedactBody, if set, rewrites captured bodies before they are emitted.
+	RedactBody func(contentType string, body []byte) []byte
+	// Every dumps one in every N requests. Defaults to 1.
+	Every int
+	// Sample, if set, selects the requests to dump instead of Every.
+	Sample func(*http.Request) bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+const redacted = "[REDACTED]"
+
+// WithDump records sampled requests and responses, including headers and
+// bodies up to MaxBodySize, to opts.Sink. Bodies are captured as they are
+// read and written, so handlers still stream. Intended for debugging and
+// staging environments.
+//
+// Example:
+//
+//	handler := HandlerFunc(api).WithDump(DumpOptions{
+//	    Sink:   WriteFunc(os.Stderr.Write),
+//	    Format: DumpHAR,
+//	    Sample: func(r *http.Request) bool { return r.Header.Get("X-Debug") == "1" },
+//	})
+func (f HandlerFunc) WithDump(opts DumpOptions) HandlerFunc {
+	if opts.Sink == nil {
+		return f
+	}
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = 64 << 10
+	}
+	if opts.RedactHeaders == nil {
+		opts.RedactHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key"}
+	}
+	if opts.Every <= 0 {
+		opts.Every = 1
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	var count atomic.Uint64
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		sampled := count.Add(1)%uint64(opts.Every) == 0
+		if opts.Sample != nil {
+			sampled = opts.Sample(r)
+		}
+		if !sampled {
+			f(w, r)
+			return
+		}
+
+		ex := &dumpExchange{req: r, reqHeader: r.Header.Clone(), started: opts.Now()}
+		reqBody := &captureBuffer{limit: opts.MaxBodySize}
+		r2 := r
+		if r.Body != nil && r.Body != http.NoBody {
+			r2 = new(http.Request)
+			*r2 = *r
+			r2.Body = struct {
+				ReadFunc
+				CloseFunc
+			}{
+				ReadFunc(r.Body.Read).Tap(func(p []byte, _ int, _ error) { reqBody.Write(p) }),
+				r.Body.Close,
+			}
+		}
+		dw := &dumpWriter{statusWriter: newStatusWriter(w), body: &captureBuffer{limit: opts.MaxBodySize}}
+		f(dw, r2)
+
+		ex.elapsed = opts.Now().Sub(ex.started)
+		ex.status = dw.status
+		if ex.status == 0 {
+			ex.status = http.StatusOK
+		}
+		ex.respHeader = w.Header().Clone()
+		ex.reqBody, ex.respBody = reqBody, dw.body
+		for _, name := range opts.RedactHeaders {
+			for _, h := range []http.Header{ex.reqHeader, ex.respHeader} {
+				if _, ok := h[http.CanonicalHeaderKey(name)]; ok {
+					h.Set(name, redacted)
+				}
+			}
+		}
+		if opts.RedactBody != nil {
+			reqBody.buf = opts.RedactBody(ex.reqHeader.Get("Content-Type"), reqBody.buf)
+			dw.body.buf = opts.RedactBody(ex.respHeader.Get("Content-Type"), dw.body.buf)
+		}
+
+		var record []byte
+		if opts.Format == DumpHAR {
+			record = ex.har()
+		} else {
+			record = ex.raw()
+		}
+		_, _ = opts.Sink(record)
+	}
+}
+
+// captureBuffer keeps the first limit bytes written and counts the rest.
+type captureBuffer struct {
+	buf   []byte
+	limit int
+	total int
+}
+
+func (c *captureBuffer) Write(p []byte) {
+	c.total += len(p)
+	if room := c.limit - len(c.buf); room > 0 {
+		c.buf = append(c.buf, p[:min(room, len(p))]...)
+	}
+}
+
+func (c *captureBuffer) truncated() bool {
+	return c.total > len(c.buf)
+}
+
+type dumpWriter struct {
+	*statusWriter
+	body *captureBuffer
+}
+
+func (dw *dumpWriter) Write(p []byte) (int, error) {
+	n, err := dw.statusWriter.Write(p)
+	dw.body.Write(p[:n])
+	return n, err
+}
+
+type dumpExchange struct {
+	req               *http.Request
+	reqHeader         http.Header
+	respHeader        http.Header
+	reqBody, respBody *captureBuffer
+	status            int
+	started           time.Time
+	elapsed           time.Duration
+}
+
+func (ex *dumpExchange) raw() []byte {
+	var b bytes.Buffer
+	req := new(http.Request)
+	*req = *ex.req
+	req.Header = ex.reqHeader
+	if head, err := httputil.DumpRequest(req, false); err == nil {
+		b.Write(head)
+	}
+	writeRawBody(&b, ex.reqBody)
+
+	fmt.Fprintf(&b, "HTTP/%d.%d %03d %s\r\n", ex.req.ProtoMajor, ex.req.ProtoMinor, ex.status, http.StatusText(ex.status))
+	_ = ex.respHeader.Write(&b)
+	b.WriteString("\r\n")
+	writeRawBody(&b, ex.respBody)
+	b.WriteString("\n")
+	return b.Bytes()
+}
+
+func writeRawBody(b *bytes.Buffer, body *captureBuffer) {
+	b.Write(body.buf)
+	if body.truncated() {
+		fmt.Fprintf(b, "\n[truncated %d of %d bytes]", body.total-len(body.buf), body.total)
+	}
+	if len(body.buf) > 0 {
+		b.WriteString("\n")
+	}
+}
+
+type harNameValue struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+func harHeaders(h http.Header) []harNameValue {
+	out := []harNameValue{}
+	for name, values := range h {
+		for _, v := range values {
+			out = append(out, harNameValue{name, v})
+		}
+	}
+	return out
+}
+
+func harText(body *captureBuffer) (text, encoding string) {
+	if utf8.Valid(body.buf) {
+		return string(body.buf), ""
+	}
+	return base64.StdEncoding.EncodeToString(body.buf), "base64"
+}
+
+func (ex *dumpExchange) har() []byte {
+	r := ex.req
+	scheme := SchemeFromContext(r.Context())
+	if scheme == "" {
+		scheme = "http"
+		if r.TLS != nil {
+			scheme = "https"
+		}
+	}
+	query := []harNameValue{}
+	for name, values := range r.URL.Query() {
+		for _, v := range values {
+			query = append(query, harNameValue{name, v})
+		}
+	}
+
+	request := map[string]any{
+		"method":      r.Method,
+		"url":         scheme + "://" + r.Host + r.URL.RequestURI(),
+		"httpVersion": r.Proto,
+		"headers":     harHeaders(ex.reqHeader),
+		"queryString": query,
+		"cookies":     []any{},
+		"headersSize": -1,
+		"bodySize":    ex.reqBody.total,
+	}
+	if ex.reqBody.total > 0 {
+		text, encoding := harText(ex.reqBody)
+		postData := map[string]any{"mimeType": ex.reqHeader.Get("Content-Type"), "text": text}
+		if encoding != "" {
+			postData["encoding"] = encoding
+		}
+		request["postData"] = postData
+	}
+
+	text, encoding := harText(ex.respBody)
+	content := map[string]any{
+		"size":     ex.respBody.total,
+		"mimeType": ex.respHeader.Get("Content-Type"),
+		"text":     text,
+	}
+	if encoding != "" {
+		content["encoding"] = encoding
+	}
+	if ex.respBody.truncated() {
+		content["comment"] = "truncated"
+	}
+	ms := float64(ex.elapsed.Microseconds()) / 1000
+
+	entry := map[string]any{
+		"startedDateTime": ex.started.Format(time.RFC3339Nano),
+		"time":            ms,
+		"request":         request,
+		"response": map[string]any{
+			"status":      ex.status,
+			"statusText":  http.StatusText(ex.status),
+			"httpVersion": r.Proto,
+			"headers":     harHeaders(ex.respHeader),
+			"cookies":     []any{},
+			"content":     content,
+			"redirectURL": ex.respHeader.Get("Location"),
+			"headersSize": -1,
+			"bodySize":    ex.respBody.total,
+		},
+		"cache":   map[string]any{},
+		"timings": map[string]any{"send": 0, "wait": ms, "receive": 0},
+	}
+	data, _ := json.Marshal(entry)
+	return append(data, '\n')
+}
+
+// RedactJSONFields returns a RedactBody function that masks the values of
+// the named top-level fields in JSON bodies. JSON bodies that do not parse,
+// including captures truncated at MaxBodySize, are replaced entirely so a
+// field cannot leak; valid JSON that is not an object is left unchanged.
+func RedactJSONFields(fields ...string) func(contentType string, body []byte) []byte {
+	return func(contentType string, body []byte) []byte {
+		if !strings.Contains(contentType, "json") || len(body) == 0 {
+			return body
+		}
+		var doc map[string]json.RawMessage
+		if json.Unmarshal(body, &doc) != nil {
+			if json.Valid(body) {
+				return body
+			}
+			return []byte(redacted)
+		}
+		changed := false
+		for _, field := range fields {
+			if _, ok := doc[field]; ok {
+				doc[field] = json.RawMessage(`"` + redacted + `"`)
+				changed = true
+			}
+		}
+		if !changed {
+			return body
+		}
+		out, err := json.Marshal(doc)
+		if err != nil {
+			return body
+		}
+		return out
+	}
+}
@@ -0,0 +1,161 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Dump Tests
+// ============================================================================
+
+func echoHandler(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Set-Cookie", "session=secret")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(body)
+}
+
+func TestHandlerFunc_WithDump_Raw(t *testing.T) {
+	var sink bytes.Buffer
+	handler := HandlerFunc(echoHandler).WithDump(DumpOptions{
+		Sink:        sink.Write,
+		MaxBodySize: 16,
+		RedactBody:  RedactJSONFields("card"),
+	})
+
+	req := httptest.NewRequest("POST", "/payments?x=1", strings.NewReader(`{"card":"4242"}`))
+	req.Header.Set("Authorization", "Bearer token")
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated || w.Body.String() != `{"card":"4242"}` {
+		t.Errorf("expected response to pass through, got %d %q", w.Code, w.Body.String())
+	}
+	dump := sink.String()
+	for _, want := range []string{
+		"POST /payments?x=1 HTTP/1.1",
+		"Authorization: [REDACTED]",
+		`{"card":"[REDACTED]"}`,
+		"HTTP/1.1 201 Created",
+		"Set-Cookie: [REDACTED]",
+	} {
+		if !strings.Contains(dump, want) {
+			t.Errorf("expected dump to contain %q, got:\n%s", want, dump)
+		}
+	}
+	if strings.Contains(dump, "4242") || strings.Contains(dump, "Bearer token") {
+		t.Errorf("expected secrets to be redacted, got:\n%s", dump)
+	}
+}
+
+func TestHandlerFunc_WithDump_HARAndTruncation(t *testing.T) {
+	var sink bytes.Buffer
+	handler := HandlerFunc(echoHandler).WithDump(DumpOptions{Sink: sink.Write, Format: DumpHAR, MaxBodySize: 4})
+
+	req := httptest.NewRequest("POST", "http://api.example.com/echo", strings.NewReader("hello world"))
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	var entry struct {
+		Request struct {
+			Method   string `json:"method"`
+			URL      string `json:"url"`
+			BodySize int    `json:"bodySize"`
+			PostData struct {
+				Text string `json:"text"`
+			} `json:"postData"`
+		} `json:"request"`
+		Response struct {
+			Status  int `json:"status"`
+			Content struct {
+				Size    int    `json:"size"`
+				Text    string `json:"text"`
+				Comment string `json:"comment"`
+			} `json:"content"`
+		} `json:"response"`
+	}
+	if err := json.Unmarshal(sink.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON HAR entry, got %v: %s", err, sink.String())
+	}
+	if entry.Request.Method != "POST" || entry.Request.URL != "http://api.example.com/echo" {
+		t.Errorf("unexpected request: %+v", entry.Request)
+	}
+	if entry.Request.BodySize != 11 || entry.Request.PostData.Text != "hell" {
+		t.Errorf("expected truncated request body, got %d %q", entry.Request.BodySize, entry.Request.PostData.Text)
+	}
+	if entry.Response.Status != 201 || entry.Response.Content.Size != 11 || entry.Response.Content.Comment != "truncated" {
+		t.Errorf("unexpected response: %+v", entry.Response)
+	}
+}
+
+func TestHandlerFunc_WithDump_Sampling(t *testing.T) {
+	records := 0
+	sink := WriteFunc(func(p []byte) (int, error) { records++; return len(p), nil })
+	every := HandlerFunc(echoHandler).WithDump(DumpOptions{Sink: sink, Every: 3})
+	for i := 0; i < 9; i++ {
+		every.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}
+	if records != 3 {
+		t.Errorf("expected every third request dumped, got %d", records)
+	}
+
+	records = 0
+	debug := HandlerFunc(echoHandler).WithDump(DumpOptions{
+		Sink:   sink,
+		Sample: func(r *http.Request) bool { return r.Header.Get("X-Debug") == "1" },
+	})
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Debug", "1")
+	debug.ServeHTTP(httptest.NewRecorder(), req)
+	debug.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if records != 1 {
+		t.Errorf("expected only flagged request dumped, got %d", records)
+	}
+}
+
+func TestRedactJSONFields(t *testing.T) {
+	redact := RedactJSONFields("password")
+	tests := []struct {
+		contentType, body, want string
+	}{
+		{"application/json", `{"user":"a","password":"hunter2"}`, `{"password":"[REDACTED]","user":"a"}`},
+		{"application/json", `{"user":"a","password":"hun`, "[REDACTED]"},
+		{"application/json", `["password"]`, `["password"]`},
+		{"text/plain", `password=hunter2`, `password=hunter2`},
+	}
+	for _, tt := range tests {
+		if got := string(redact(tt.contentType, []byte(tt.body))); got != tt.want {
+			t.Errorf("%s %q: expected %q, got %q", tt.contentType, tt.body, tt.want, got)
+		}
+	}
+}
+
+func TestHandlerFunc_WithDump_HARBinaryRequest(t *testing.T) {
+	var sink bytes.Buffer
+	handler := HandlerFunc(echoHandler).WithDump(DumpOptions{Sink: sink.Write, Format: DumpHAR})
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/echo", bytes.NewReader([]byte{0xff, 0xfe})))
+
+	var entry struct {
+		Request struct {
+			PostData struct {
+				Text     string `json:"text"`
+				Encoding string `json:"encoding"`
+			} `json:"postData"`
+		} `json:"request"`
+	}
+	if err := json.Unmarshal(sink.Bytes(), &entry); err != nil {
+		t.Fatalf("expected a JSON HAR entry, got %v", err)
+	}
+	if entry.Request.PostData.Encoding != "base64" || entry.Request.PostData.Text != "//4=" {
+		t.Errorf("expected base64 postData, got %+v", entry.Request.PostData)
+	}
+}
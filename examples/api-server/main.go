@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
@@ -42,27 +43,20 @@ func main() {
 		Recover()
 
 	// POST /users - requires auth
-	createUser := pfc.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var user User
-		if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-			http.Error(w, "Invalid JSON", http.StatusBadRequest)
-			return
-		}
-
-		user.ID = "3" // Generate ID
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusCreated)
-		json.NewEncoder(w).Encode(user)
-	}).
+	createUser := pfc.JSONHandlerWith(pfc.JSONOptions{Status: http.StatusCreated},
+		func(ctx context.Context, user User) (User, error) {
+			user.ID = "3" // Generate ID
+			return user, nil
+		}).
 		WithLogging(logger).
 		WithAuth(isAuthenticated).
 		WithTimeout(5 * time.Second).
 		WithCORS("*").
 		Recover()
 
-	// GET /users/:id - requires auth, has timeout
+	// GET /users/{id} - requires auth, has timeout
 	getUser := pfc.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		id := r.URL.Query().Get("id")
+		id := r.PathValue("id")
 		user := User{ID: id, Name: "Alice", Email: "alice@example.com"}
 
 		w.Header().Set("Content-Type", "application/json")
@@ -74,9 +68,9 @@ func main() {
 		WithCORS("*").
 		Recover()
 
-	// DELETE /users/:id - admin only (stricter auth)
+	// DELETE /users/{id} - admin only (stricter auth)
 	deleteUser := pfc.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		id := r.URL.Query().Get("id")
+		id := r.PathValue("id")
 		w.WriteHeader(http.StatusNoContent)
 		log.Printf("Deleted user %s", id)
 	}).
@@ -89,30 +83,36 @@ func main() {
 		Recover()
 
 	// Register routes
-	http.Handle("/users", methodRouter(map[string]http.Handler{
-		"GET":  listUsers,
-		"POST": createUser,
-	}))
-	http.Handle("/users/", methodRouter(map[string]http.Handler{
-		"GET":    getUser,
-		"DELETE": deleteUser,
-	}))
+	router := pfc.NewRouter()
+	router.Get("/users", listUsers)
+	router.Post("/users", createUser)
+	router.Get("/users/{id}", getUser)
+	router.Delete("/users/{id}", deleteUser)
+
+	// "/users/" and "//users" redirect to "/users", so each route is
+	// registered once.
+	root := pfc.HandlerFunc(router.ServeHTTP).
+		WithPathNormalization(pfc.PathOptions{TrailingSlash: pfc.TrailingSlashStrip})
+
+	// Graceful shutdown on Ctrl+C or SIGTERM
+	srv := pfc.NewServer(root, pfc.ServerConfig{
+		Addr:            ":8080",
+		ShutdownTimeout: 10 * time.Second,
+		Logger:          logger,
+	})
+	router.Get("/livez", srv.Live())
+	router.Get("/readyz", srv.Ready())
+	srv.OnShutdown(func() error {
+		log.Println("Closing connections to backing services")
+		return nil
+	})
 
 	log.Println("🚀 Server running on :8080")
 	log.Println("Try:")
 	log.Println("  curl http://localhost:8080/users")
-	log.Println("  curl -H 'Authorization: Bearer token' http://localhost:8080/users?id=1")
-
-	http.ListenAndServe(":8080", nil)
-}
+	log.Println("  curl -H 'Authorization: Bearer token' http://localhost:8080/users/1")
 
-// Helper to route by HTTP method
-func methodRouter(handlers map[string]http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if handler, ok := handlers[r.Method]; ok {
-			handler.ServeHTTP(w, r)
-		} else {
-			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
-		}
-	})
+	if err := srv.ListenAndServe(context.Background()); err != nil {
+		log.Fatal(err)
+	}
 }
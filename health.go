@@ -0,0 +1,270 @@
+package purefunccore
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Health Checks
+// ============================================================================
+
+// HealthCheckFunc reports whether a dependency is healthy.
+// Methods such as (*sql.DB).PingContext can be used directly.
+type HealthCheckFunc func(ctx context.Context) error
+
+// HealthCheck is a named check registered with a HealthChecker.
+type HealthCheck struct {
+	Name  string
+	Check HealthCheckFunc
+	// Timeout bounds a single run. Defaults to HealthOptions.Timeout.
+	Timeout time.Duration
+	// Critical failures make the report fail with 503. Other failures
+	// only downgrade it to "warn".
+	Critical bool
+	// Liveness includes the check in the liveness report. Dependencies
+	// should usually be readiness-only, so an outage does not restart
+	// every instance.
+	Liveness bool
+	// CacheTTL reuses the last result for this long, protecting slow or
+	// rate-limited dependencies from frequent probes.
+	CacheTTL time.Duration
+}
+
+// HealthOptions configures NewHealthChecker.
+type HealthOptions struct {
+	// Timeout is the default per-check timeout. Defaults to 5 seconds.
+	Timeout time.Duration
+	// ExposeErrors includes check error messages in the Live and Ready
+	// responses. They are omitted by default because they can reveal DSNs
+	// and internal hostnames on a public endpoint; Run always includes them
+	// for logging.
+	ExposeErrors bool
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Health statuses used in reports.
+const (
+	HealthPass = "pass"
+	HealthWarn = "warn"
+	HealthFail = "fail"
+)
+
+// HealthResult is the outcome of one check.
+type HealthResult struct {
+	Status     string    `json:"status"`
+	Error      string    `json:"error,omitempty"`
+	Critical   bool      `json:"critical"`
+	DurationMS float64   `json:"duration_ms"`
+	CheckedAt  time.Time `json:"checked_at"`
+}
+
+// HealthReport aggregates check results.
+type HealthReport struct {
+	Status string                  `json:"status"`
+	Checks map[string]HealthResult `json:"checks,omitempty"`
+}
+
+// HealthChecker runs registered checks concurrently and reports on them.
+type HealthChecker struct {
+	opts HealthOptions
+
+	mu     sync.Mutex
+	checks []*registeredCheck
+}
+
+type registeredCheck struct {
+	HealthCheck
+	mu   sync.Mutex
+	last *HealthResult
+}
+
+// NewHealthChecker creates a checker with no checks.
+//
+// Example:
+//
+//	health := NewHealthChecker(HealthOptions{})
+//	health.Register(HealthCheck{Name: "postgres", Check: db.PingContext, Critical: true})
+//	health.Register(HealthCheck{
+//	    Name:     "payments-api",
+//	    Check:    HTTPHealthCheck(client.Transport, "https://payments.internal/ping"),
+//	    CacheTTL: 10 * time.Second,
+//	})
+//	router.Get("/livez", health.Live())
+//	router.Get("/readyz", health.Ready())
+func NewHealthChecker(opts HealthOptions) *HealthChecker {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Second
+	}
+	if opts.Now == nil {
+		opts.Now = time.Now
+	}
+	return &HealthChecker{opts: opts}
+}
+
+// Register adds a check.
+func (h *HealthChecker) Register(check HealthCheck) {
+	if check.Timeout <= 0 {
+		check.Timeout = h.opts.Timeout
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, &registeredCheck{HealthCheck: check})
+}
+
+// Run executes the checks concurrently and aggregates their results.
+// With liveness set, only checks marked Liveness run.
+func (h *HealthChecker) Run(ctx context.Context, liveness bool) HealthReport {
+	h.mu.Lock()
+	checks := make([]*registeredCheck, 0, len(h.checks))
+	for _, c := range h.checks {
+		if !liveness || c.Liveness {
+			checks = append(checks, c)
+		}
+	}
+	h.mu.Unlock()
+
+	results := make([]HealthResult, len(checks))
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, c)
+		}()
+	}
+	wg.Wait()
+
+	report := HealthReport{Status: HealthPass, Checks: make(map[string]HealthResult, len(checks))}
+	for i, c := range checks {
+		res := results[i]
+		report.Checks[c.Name] = res
+		switch {
+		case res.Status == HealthFail && c.Critical:
+			report.Status = HealthFail
+		case res.Status != HealthPass && report.Status == HealthPass:
+			report.Status = HealthWarn
+		}
+	}
+	return report
+}
+
+func (h *HealthChecker) run(ctx context.Context, c *registeredCheck) HealthResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := h.opts.Now()
+	if c.last != nil && c.CacheTTL > 0 && now.Sub(c.last.CheckedAt) < c.CacheTTL {
+		return *c.last
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if v := recover(); v != nil {
+				done <- fmt.Errorf("check panicked: %v", v)
+			}
+		}()
+		done <- c.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		// The check ignored its context; report the timeout without waiting.
+		err = ctx.Err()
+	}
+
+	res := HealthResult{
+		Status:     HealthPass,
+		Critical:   c.Critical,
+		DurationMS: float64(h.opts.Now().Sub(now).Microseconds()) / 1000,
+		CheckedAt:  now,
+	}
+	if err != nil {
+		res.Status = HealthFail
+		res.Error = err.Error()
+	}
+	c.last = &res
+	return res
+}
+
+// Live returns a liveness handler running only checks marked Liveness.
+func (h *HealthChecker) Live() HandlerFunc {
+	return h.handler(true)
+}
+
+// Ready returns a readiness handler running every check. It replies 503
+// when a critical check fails and 200 otherwise. When serving behind a
+// Server, register Server.HealthCheck so readiness also fails while the
+// server drains.
+func (h *HealthChecker) Ready() HandlerFunc {
+	return h.handler(false)
+}
+
+func (h *HealthChecker) handler(liveness bool) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := h.Run(r.Context(), liveness)
+		if !h.opts.ExposeErrors {
+			for name, res := range report.Checks {
+				res.Error = ""
+				report.Checks[name] = res
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		if report.Status == HealthFail {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		_ = json.NewEncoder(w).Encode(report)
+	}
+}
+
+// DriverHealthCheck opens a connection with d, pings it when the connection
+// implements driver.Pinger, and closes it. DriverFunc values can be used,
+// which keeps database checks testable without a server.
+func DriverHealthCheck(d driver.Driver, dsn string) HealthCheckFunc {
+	return func(ctx context.Context) error {
+		conn, err := d.Open(dsn)
+		if err != nil {
+			return err
+		}
+		defer conn.Close()
+		if p, ok := conn.(driver.Pinger); ok {
+			return p.Ping(ctx)
+		}
+		return nil
+	}
+}
+
+// HTTPHealthCheck sends GET url through rt and fails on transport errors
+// and 5xx or 4xx responses. A nil rt uses http.DefaultTransport; a
+// RoundTripperFunc can be passed to reuse a decorated client transport.
+func HTTPHealthCheck(rt http.RoundTripper, url string) HealthCheckFunc {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 400 {
+			return fmt.Errorf("%s returned %s", url, resp.Status)
+		}
+		return nil
+	}
+}
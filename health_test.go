@@ -0,0 +1,170 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Health Check Tests
+// ============================================================================
+
+func TestHealthChecker_Ready(t *testing.T) {
+	tests := []struct {
+		name           string
+		dbErr, apiErr  error
+		expectedStatus string
+		expectedCode   int
+	}{
+		{"all healthy", nil, nil, HealthPass, http.StatusOK},
+		{"non-critical failing", nil, errors.New("api down"), HealthWarn, http.StatusOK},
+		{"critical failing", errors.New("db down"), nil, HealthFail, http.StatusServiceUnavailable},
+	}
+	for _, tt := range tests {
+		health := NewHealthChecker(HealthOptions{ExposeErrors: true})
+		health.Register(HealthCheck{Name: "db", Critical: true, Check: func(ctx context.Context) error { return tt.dbErr }})
+		health.Register(HealthCheck{Name: "api", Check: func(ctx context.Context) error { return tt.apiErr }})
+
+		w := httptest.NewRecorder()
+		health.Ready().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+
+		var report HealthReport
+		json.NewDecoder(w.Body).Decode(&report)
+		if w.Code != tt.expectedCode || report.Status != tt.expectedStatus {
+			t.Errorf("%s: expected %d %s, got %d %s", tt.name, tt.expectedCode, tt.expectedStatus, w.Code, report.Status)
+		}
+		if len(report.Checks) != 2 {
+			t.Errorf("%s: expected 2 checks in report, got %d", tt.name, len(report.Checks))
+		}
+		if tt.dbErr != nil && report.Checks["db"].Error != tt.dbErr.Error() {
+			t.Errorf("%s: expected db error in report, got '%s'", tt.name, report.Checks["db"].Error)
+		}
+	}
+}
+
+func TestHealthChecker_HidesErrorsByDefault(t *testing.T) {
+	health := NewHealthChecker(HealthOptions{})
+	health.Register(HealthCheck{Name: "db", Critical: true, Check: func(ctx context.Context) error {
+		return errors.New("dial tcp db.internal:5432: connection refused")
+	}})
+
+	w := httptest.NewRecorder()
+	health.Ready().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "db.internal") {
+		t.Errorf("expected error details to be omitted, got %s", w.Body.String())
+	}
+	if report := health.Run(context.Background(), false); report.Checks["db"].Error == "" {
+		t.Error("expected Run to keep the error for logging")
+	}
+}
+
+func TestHealthChecker_LiveOnlyRunsLivenessChecks(t *testing.T) {
+	health := NewHealthChecker(HealthOptions{})
+	health.Register(HealthCheck{Name: "goroutines", Liveness: true, Check: func(ctx context.Context) error { return nil }})
+	health.Register(HealthCheck{Name: "db", Critical: true, Check: func(ctx context.Context) error { return errors.New("down") }})
+
+	w := httptest.NewRecorder()
+	health.Live().ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+
+	var report HealthReport
+	json.NewDecoder(w.Body).Decode(&report)
+	if w.Code != http.StatusOK || len(report.Checks) != 1 {
+		t.Errorf("expected liveness to ignore dependencies, got %d with %v", w.Code, report.Checks)
+	}
+}
+
+func TestHealthChecker_ConcurrentTimeoutAndPanic(t *testing.T) {
+	health := NewHealthChecker(HealthOptions{Timeout: 20 * time.Millisecond})
+	started := make(chan struct{}, 2)
+	barrier := func(ctx context.Context) error {
+		started <- struct{}{}
+		for len(started) < 2 {
+			select {
+			case <-ctx.Done():
+				return errors.New("checks did not run concurrently")
+			case <-time.After(time.Millisecond):
+			}
+		}
+		return nil
+	}
+	health.Register(HealthCheck{Name: "a", Critical: true, Check: barrier, Timeout: time.Second})
+	health.Register(HealthCheck{Name: "b", Critical: true, Check: barrier, Timeout: time.Second})
+	health.Register(HealthCheck{Name: "stuck", Check: func(ctx context.Context) error { time.Sleep(200 * time.Millisecond); return nil }})
+	health.Register(HealthCheck{Name: "panics", Check: func(ctx context.Context) error { panic("boom") }})
+
+	report := health.Run(context.Background(), false)
+	if report.Checks["a"].Status != HealthPass || report.Checks["b"].Status != HealthPass {
+		t.Errorf("expected concurrent checks to pass, got %+v", report.Checks)
+	}
+	if report.Checks["stuck"].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("expected timeout, got '%s'", report.Checks["stuck"].Error)
+	}
+	if report.Checks["panics"].Status != HealthFail {
+		t.Error("expected panicking check to fail")
+	}
+	if report.Status != HealthWarn {
+		t.Errorf("expected warn, got %s", report.Status)
+	}
+}
+
+func TestHealthChecker_CachesResults(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1000, 0)}
+	calls := 0
+	health := NewHealthChecker(HealthOptions{Now: clock.Now})
+	health.Register(HealthCheck{Name: "slow", CacheTTL: 10 * time.Second, Check: func(ctx context.Context) error {
+		calls++
+		return nil
+	}})
+
+	health.Run(context.Background(), false)
+	clock.Advance(5 * time.Second)
+	health.Run(context.Background(), false)
+	if calls != 1 {
+		t.Errorf("expected cached result, got %d calls", calls)
+	}
+	clock.Advance(5 * time.Second)
+	health.Run(context.Background(), false)
+	if calls != 2 {
+		t.Errorf("expected check to rerun after TTL, got %d calls", calls)
+	}
+}
+
+func TestDriverAndHTTPHealthChecks(t *testing.T) {
+	closed := false
+	healthyDB := DriverFunc(func(name string) (driver.Conn, error) {
+		return ConnFunc{CloseFunc: func() error { closed = true; return nil }}, nil
+	})
+	downDB := DriverFunc(func(name string) (driver.Conn, error) {
+		return nil, errors.New("connection refused")
+	})
+	if err := DriverHealthCheck(healthyDB, "dsn")(context.Background()); err != nil || !closed {
+		t.Errorf("expected healthy driver check to pass and close, got %v", err)
+	}
+	if err := DriverHealthCheck(downDB, "dsn")(context.Background()); err == nil {
+		t.Error("expected failing driver check")
+	}
+
+	status := http.StatusOK
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: http.NoBody}, nil
+	})
+	check := HTTPHealthCheck(transport, "http://payments/ping")
+	if err := check(context.Background()); err != nil {
+		t.Errorf("expected 200 to pass, got %v", err)
+	}
+	status = http.StatusBadGateway
+	if err := check(context.Background()); err == nil {
+		t.Error("expected 502 to fail")
+	}
+}
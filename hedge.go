@@ -0,0 +1,163 @@
+package purefunccore
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// ============================================================================
+// Hedged and Raced Requests
+// ============================================================================
+
+// Hedge cuts tail latency by sending up to maxExtra duplicate requests,
+// one every delay, while no response has arrived. The first successful
+// response (no error and a status below 500) wins; the others are cancelled
+// and their bodies drained. A failed attempt starts the next one at once.
+//
+// Only idempotent requests, or those carrying an Idempotency-Key, are
+// hedged, and request bodies must be rewindable via GetBody.
+//
+// Example:
+//
+//	transport := RoundTripperFunc(nil).Empty().
+//	    Hedge(50*time.Millisecond, 2).
+//	    WithTimeout(2 * time.Second)
+func (f RoundTripperFunc) Hedge(delay time.Duration, maxExtra int) RoundTripperFunc {
+	attempts := make([]RoundTripperFunc, max(maxExtra, 0)+1)
+	for i := range attempts {
+		attempts[i] = f
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		if maxExtra <= 0 || !isRetryable(req) {
+			return f(req)
+		}
+		return hedge(req, delay, attempts)
+	}
+}
+
+// RaceTransports sends the request through every transport at once, such as
+// one per region, and returns the first successful response. Losers are
+// cancelled and drained; if every transport fails the last failure is
+// returned. Requests that are not idempotent go to the first transport only.
+//
+// Example:
+//
+//	transport := RaceTransports(
+//	    RoundTripperFunc(nil).Empty().WithBaseURL("https://eu.api.example.com"),
+//	    RoundTripperFunc(nil).Empty().WithBaseURL("https://us.api.example.com"),
+//	)
+func RaceTransports(transports ...RoundTripperFunc) RoundTripperFunc {
+	if len(transports) == 0 {
+		panic("purefunccore: RaceTransports requires at least one transport")
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		if len(transports) == 1 || !isRetryable(req) {
+			return transports[0](req)
+		}
+		return hedge(req, 0, transports)
+	}
+}
+
+type hedgeResult struct {
+	attempt int
+	resp    *http.Response
+	err     error
+	cancel  context.CancelFunc
+}
+
+// hedge starts attempts[0] immediately and each following attempt after
+// delay, or as soon as the previous one fails, until one succeeds.
+func hedge(req *http.Request, delay time.Duration, attempts []RoundTripperFunc) (*http.Response, error) {
+	results := make(chan hedgeResult, len(attempts))
+	cancels := make([]context.CancelFunc, 0, len(attempts))
+	pending := 0
+
+	start := func() bool {
+		r := req
+		if len(cancels) > 0 {
+			var err error
+			if r, err = rewindRequest(req); err != nil {
+				return false
+			}
+		}
+		ctx, cancel := context.WithCancel(req.Context())
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		pending++
+		go func(r *http.Request) {
+			resp, err := attempts[i](r)
+			results <- hedgeResult{i, resp, err, cancel}
+		}(r.WithContext(ctx))
+		return true
+	}
+	start()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	tick := timer.C
+	next := func() {
+		if len(cancels) < len(attempts) && start() && len(cancels) < len(attempts) {
+			timer.Reset(delay)
+			return
+		}
+		// Out of attempts, or the body cannot be sent again.
+		tick = nil
+	}
+	if len(attempts) == 1 {
+		tick = nil
+	}
+
+	var last hedgeResult
+	for pending > 0 {
+		select {
+		case <-tick:
+			next()
+		case res := <-results:
+			pending--
+			if last.cancel != nil {
+				last.discard()
+			}
+			if res.err == nil && res.resp.StatusCode < http.StatusInternalServerError {
+				for i, cancel := range cancels {
+					if i != res.attempt {
+						cancel()
+					}
+				}
+				go func(n int) {
+					for ; n > 0; n-- {
+						(<-results).discard()
+					}
+				}(pending)
+				return res.keep()
+			}
+			last = res
+			if tick != nil {
+				next()
+			}
+		}
+	}
+	return last.keep()
+}
+
+// keep returns the result, releasing its context when the body is closed.
+func (r hedgeResult) keep() (*http.Response, error) {
+	if r.err != nil {
+		r.cancel()
+		return nil, r.err
+	}
+	if r.resp.Body == nil {
+		r.resp.Body = http.NoBody
+	}
+	r.resp.Body = cancelOnClose{ReadCloser: r.resp.Body, cancel: r.cancel}
+	return r.resp, nil
+}
+
+// discard cancels a losing attempt and drains its body so the connection
+// can be reused.
+func (r hedgeResult) discard() {
+	r.cancel()
+	if r.resp != nil {
+		drainBody(r.resp.Body)
+	}
+}
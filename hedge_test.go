@@ -0,0 +1,177 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Hedged and Raced Request Tests
+// ============================================================================
+
+// hedgeBody records whether a response body was closed.
+type hedgeBody struct {
+	io.Reader
+	closed *atomic.Bool
+}
+
+func (b hedgeBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+func okResponse(body string, closed *atomic.Bool) *http.Response {
+	return &http.Response{StatusCode: http.StatusOK, Body: hedgeBody{strings.NewReader(body), closed}}
+}
+
+func TestRoundTripperFunc_Hedge(t *testing.T) {
+	var calls atomic.Int32
+	var slowClosed, slowCanceled atomic.Bool
+	release := make(chan struct{})
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		if calls.Add(1) == 1 {
+			select {
+			case <-req.Context().Done():
+				slowCanceled.Store(true)
+			case <-release:
+			}
+			return okResponse("slow", &slowClosed), nil
+		}
+		return okResponse("fast", new(atomic.Bool)), nil
+	}).Hedge(10*time.Millisecond, 2)
+
+	resp, err := transport(httptest.NewRequest("GET", "http://example.com/", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "fast" {
+		t.Errorf("expected hedged response 'fast', got '%s'", body)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls.Load())
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for !slowClosed.Load() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if !slowCanceled.Load() || !slowClosed.Load() {
+		t.Errorf("expected losing attempt to be cancelled and drained")
+	}
+	close(release)
+}
+
+func TestRoundTripperFunc_Hedge_FastResponse(t *testing.T) {
+	var calls atomic.Int32
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		return okResponse("ok", new(atomic.Bool)), nil
+	}).Hedge(time.Second, 2)
+
+	start := time.Now()
+	resp, _ := transport(httptest.NewRequest("GET", "http://example.com/", nil))
+	resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Errorf("expected a single attempt, got %d", calls.Load())
+	}
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected response without waiting for the hedge delay")
+	}
+}
+
+func TestRoundTripperFunc_Hedge_FailureStartsNext(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	var calls atomic.Int32
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		b, _ := io.ReadAll(req.Body)
+		mu.Lock()
+		bodies = append(bodies, string(b))
+		mu.Unlock()
+		if calls.Add(1) == 1 {
+			return nil, errors.New("connection reset")
+		}
+		return okResponse("ok", new(atomic.Bool)), nil
+	}).Hedge(time.Hour, 1)
+
+	req := httptest.NewRequest("POST", "http://example.com/", nil)
+	body := "payload"
+	req.Body = io.NopCloser(strings.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(strings.NewReader(body)), nil }
+	req.Header.Set("Idempotency-Key", "k1")
+
+	resp, err := transport(req)
+	if err != nil {
+		t.Fatalf("expected failover to the hedge, got %v", err)
+	}
+	resp.Body.Close()
+	if len(bodies) != 2 || bodies[0] != body || bodies[1] != body {
+		t.Errorf("expected body to be rewound for each attempt, got %q", bodies)
+	}
+}
+
+func TestRoundTripperFunc_Hedge_NotIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		calls.Add(1)
+		time.Sleep(20 * time.Millisecond)
+		return &http.Response{StatusCode: http.StatusCreated, Body: http.NoBody}, nil
+	}).Hedge(time.Millisecond, 3)
+
+	resp, _ := transport(httptest.NewRequest("POST", "http://example.com/", nil))
+	resp.Body.Close()
+	if calls.Load() != 1 {
+		t.Errorf("expected POST not to be hedged, got %d attempts", calls.Load())
+	}
+}
+
+func TestRaceTransports(t *testing.T) {
+	down := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("region down")
+	})
+	unhealthy := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: http.NoBody}, nil
+	})
+	healthy := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		time.Sleep(10 * time.Millisecond)
+		return okResponse("us", new(atomic.Bool)), nil
+	})
+
+	resp, err := RaceTransports(down, unhealthy, healthy)(httptest.NewRequest("GET", "http://example.com/", nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "us" {
+		t.Errorf("expected healthy region to win, got '%s'", body)
+	}
+
+	resp, err = RaceTransports(down, down)(httptest.NewRequest("GET", "http://example.com/", nil))
+	if err == nil || resp != nil {
+		t.Errorf("expected error when every transport fails, got %v", resp)
+	}
+
+	slowUnhealthy := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		time.Sleep(10 * time.Millisecond)
+		return unhealthy(req)
+	})
+	resp, err = RaceTransports(down, slowUnhealthy)(httptest.NewRequest("GET", "http://example.com/", nil))
+	if err != nil {
+		t.Fatalf("expected last failure to be returned, got %v", err)
+	}
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", resp.StatusCode)
+	}
+	resp.Body.Close()
+}
@@ -0,0 +1,213 @@
+package purefunccore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Idempotency Keys
+// ============================================================================
+
+// IdempotencyRecord is the state stored for an Idempotency-Key.
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request body, method and URL.
+	Fingerprint string
+	// Completed is false while the original request is in flight.
+	Completed bool
+	Status    int
+	Header    http.Header
+	Body      []byte
+}
+
+// IdempotencyStore persists idempotency records. Implementations must make
+// Reserve atomic so that concurrent requests with one key cannot both win.
+type IdempotencyStore interface {
+	// Reserve records an in-flight request for key. If key already has a
+	// record, Reserve returns it and reports false.
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error)
+	// Complete stores the final response for a reserved key.
+	Complete(ctx context.Context, key string, rec *IdempotencyRecord, ttl time.Duration) error
+	// Release drops a reservation so the request can be retried.
+	Release(ctx context.Context, key string) error
+}
+
+// WithIdempotency makes unsafe requests carrying an Idempotency-Key header
+// safe to retry. The first request runs the handler and its response is
+// stored for ttl, keyed by the header value and the request Principal;
+// retries receive the stored response with Idempotent-Replayed: true.
+// A retry arriving while the first is still running, or reusing the key
+// for a different request, is rejected with 409 Conflict.
+//
+// Responses with a 5xx status, and streamed responses, are not stored, so
+// the client may retry them. The request body is read in full to compute
+// its fingerprint; combine with WithMaxBodySize to bound it. A ttl of zero
+// or less defaults to 24 hours.
+//
+// Example:
+//
+//	handler := HandlerFunc(createPayment).
+//	    WithIdempotency(NewMemoryIdempotencyStore(), 24*time.Hour).
+//	    WithJWT(jwtConfig)
+func (f HandlerFunc) WithIdempotency(store IdempotencyStore, ttl time.Duration) HandlerFunc {
+	if store == nil {
+		store = NewMemoryIdempotencyStore()
+	}
+	if ttl <= 0 {
+		ttl = 24 * time.Hour
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		idemKey := r.Header.Get("Idempotency-Key")
+		if idemKey == "" || isSafeMethod(r.Method) {
+			f(w, r)
+			return
+		}
+
+		var body []byte
+		r2 := r
+		if r.Body != nil {
+			var err error
+			if body, err = io.ReadAll(r.Body); err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			r2 = new(http.Request)
+			*r2 = *r
+			r2.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		fingerprint := requestFingerprint(r, body)
+
+		key := idemKey
+		if p, ok := PrincipalFromContext(r.Context()); ok {
+			key = p.Subject + "\x00" + idemKey
+		}
+
+		ctx := r.Context()
+		existing, ok, err := store.Reserve(ctx, key, fingerprint, ttl)
+		if err != nil {
+			http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			switch {
+			case existing.Fingerprint != fingerprint:
+				http.Error(w, "Idempotency-Key reused for a different request", http.StatusConflict)
+			case !existing.Completed:
+				http.Error(w, "A request with this Idempotency-Key is in progress", http.StatusConflict)
+			default:
+				for k, v := range existing.Header {
+					w.Header()[k] = v
+				}
+				w.Header().Set("Idempotent-Replayed", "true")
+				w.WriteHeader(existing.Status)
+				_, _ = w.Write(existing.Body)
+			}
+			return
+		}
+
+		completed := false
+		defer func() {
+			if !completed {
+				_ = store.Release(context.WithoutCancel(ctx), key)
+			}
+		}()
+
+		rb := newResponseBuffer(w)
+		f(rb, r2)
+		if rb.streaming {
+			return
+		}
+		if rb.status == 0 {
+			rb.status = http.StatusOK
+		}
+		if rb.status < 500 {
+			rec := &IdempotencyRecord{
+				Fingerprint: fingerprint,
+				Completed:   true,
+				Status:      rb.status,
+				Header:      rb.header.Clone(),
+				Body:        append([]byte(nil), rb.body.Bytes()...),
+			}
+			completed = store.Complete(context.WithoutCancel(ctx), key, rec, ttl) == nil
+		}
+		rb.writeTo(w)
+	}
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+r.URL.RequestURI()+"\n")
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// MemoryIdempotencyStore is an in-process IdempotencyStore.
+// Expired records are evicted lazily.
+type MemoryIdempotencyStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryIdempotencyEntry
+	lastSweep time.Time
+}
+
+type memoryIdempotencyEntry struct {
+	rec     *IdempotencyRecord
+	expires time.Time
+}
+
+// NewMemoryIdempotencyStore creates an empty in-memory store.
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{records: make(map[string]*memoryIdempotencyEntry)}
+}
+
+// Reserve implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(s.lastSweep) >= time.Minute {
+		for k, e := range s.records {
+			if !now.Before(e.expires) {
+				delete(s.records, k)
+			}
+		}
+		s.lastSweep = now
+	}
+	if e, ok := s.records[key]; ok && now.Before(e.expires) {
+		return e.rec, false, nil
+	}
+	s.records[key] = &memoryIdempotencyEntry{
+		rec:     &IdempotencyRecord{Fingerprint: fingerprint},
+		expires: now.Add(ttl),
+	}
+	return nil, true, nil
+}
+
+// Complete implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, key string, rec *IdempotencyRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = &memoryIdempotencyEntry{rec: rec, expires: time.Now().Add(ttl)}
+	return nil
+}
+
+// Release implements IdempotencyStore.
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+// Len returns the number of stored keys, including expired ones not yet evicted.
+func (s *MemoryIdempotencyStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
@@ -0,0 +1,153 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Idempotency Tests
+// ============================================================================
+
+func TestHandlerFunc_WithIdempotency(t *testing.T) {
+	var charges int32
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&charges, 1)
+		w.Header().Set("Location", fmt.Sprintf("/payments/%d", n))
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, "TXN-%d", n)
+	}).WithIdempotency(NewMemoryIdempotencyStore(), time.Hour)
+
+	pay := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/payments", strings.NewReader(body))
+		if key != "" {
+			req.Header.Set("Idempotency-Key", key)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	first := pay("k1", `{"amount":100}`)
+	retry := pay("k1", `{"amount":100}`)
+	if first.Code != http.StatusCreated || retry.Code != http.StatusCreated {
+		t.Fatalf("expected 201 twice, got %d and %d", first.Code, retry.Code)
+	}
+	if retry.Body.String() != "TXN-1" || retry.Header().Get("Location") != "/payments/1" {
+		t.Errorf("expected replayed response, got %q at %s", retry.Body.String(), retry.Header().Get("Location"))
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Error("expected Idempotent-Replayed header on retry")
+	}
+	if charges != 1 {
+		t.Errorf("expected 1 charge, got %d", charges)
+	}
+
+	if w := pay("k1", `{"amount":999}`); w.Code != http.StatusConflict {
+		t.Errorf("expected 409 for a different request, got %d", w.Code)
+	}
+	pay("", `{"amount":100}`)
+	pay("", `{"amount":100}`)
+	if charges != 3 {
+		t.Errorf("expected requests without a key to run, got %d charges", charges)
+	}
+}
+
+func TestHandlerFunc_WithIdempotency_Concurrent(t *testing.T) {
+	// A zero ttl must not let the reservation expire immediately.
+	for _, ttl := range []time.Duration{time.Hour, 0} {
+		started := make(chan struct{})
+		finish := make(chan struct{})
+		handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			close(started)
+			<-finish
+		}).WithIdempotency(NewMemoryIdempotencyStore(), ttl)
+
+		newReq := func() *http.Request {
+			req := httptest.NewRequest("POST", "/", strings.NewReader("x"))
+			req.Header.Set("Idempotency-Key", "k")
+			return req
+		}
+
+		done := make(chan struct{})
+		go func() {
+			handler.ServeHTTP(httptest.NewRecorder(), newReq())
+			close(done)
+		}()
+		<-started
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newReq())
+		if w.Code != http.StatusConflict {
+			t.Errorf("ttl %s: expected 409 while in flight, got %d", ttl, w.Code)
+		}
+		close(finish)
+		<-done
+	}
+}
+
+func TestHandlerFunc_WithIdempotency_ServerErrorRetryable(t *testing.T) {
+	var calls int32
+	store := NewMemoryIdempotencyStore()
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			http.Error(w, "database down", http.StatusServiceUnavailable)
+		}
+	}).WithIdempotency(store, time.Hour)
+
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("Idempotency-Key", "k")
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("expected 5xx response to allow a retry, got %d calls", calls)
+	}
+	if store.Len() != 1 {
+		t.Errorf("expected successful retry to be stored, got %d records", store.Len())
+	}
+}
+
+func TestHandlerFunc_WithIdempotency_ScopedToPrincipal(t *testing.T) {
+	var calls int32
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}).WithIdempotency(nil, time.Hour)
+
+	for _, subject := range []string{"alice", "bob"} {
+		req := httptest.NewRequest("POST", "/", nil)
+		req.Header.Set("Idempotency-Key", "same")
+		req = req.WithContext(ContextWithPrincipal(req.Context(), &Principal{Subject: subject}))
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("expected keys scoped per principal, got %d calls", calls)
+	}
+}
+
+func TestHandlerFunc_WithIdempotency_DoesNotModifyRequest(t *testing.T) {
+	var got string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := io.ReadAll(r.Body)
+		got = string(b)
+	}).WithIdempotency(NewMemoryIdempotencyStore(), time.Hour)
+
+	req := httptest.NewRequest("POST", "/payments", strings.NewReader("body"))
+	req.Header.Set("Idempotency-Key", "k")
+	body := req.Body
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if got != "body" {
+		t.Errorf("expected handler to read the body, got %q", got)
+	}
+	if req.Body != body {
+		t.Error("expected the caller's request to be left unchanged")
+	}
+}
@@ -0,0 +1,299 @@
+package purefunccore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ============================================================================
+// Typed JSON Handlers
+// ============================================================================
+
+// Validator is implemented by request types that can check themselves.
+// JSONHandler calls Validate after decoding and replies 422 on error.
+type Validator interface {
+	Validate() error
+}
+
+// Encoder writes a response value in a specific media type.
+type Encoder func(w io.Writer, v any) error
+
+// JSONOptions configures JSONHandlerWith.
+type JSONOptions struct {
+	// MaxBodyBytes limits the request body. Defaults to 1 MiB.
+	MaxBodyBytes int64
+	// AllowUnknownFields disables DisallowUnknownFields on the decoder.
+	AllowUnknownFields bool
+	// Status is the success status code. Defaults to 200.
+	Status int
+	// Encoders maps media types to response encoders for content
+	// negotiation. Defaults to application/json only.
+	Encoders map[string]Encoder
+	// Problem maps handler errors to problem details. Defaults to ProblemFor.
+	Problem func(error) *Problem
+}
+
+// JSONHandler adapts a typed function into a HandlerFunc.
+// The request body is decoded into Req (an empty body leaves it zero),
+// validated if Req implements Validator, and the result is encoded in the
+// negotiated media type. Errors become RFC 9457 problem+json responses.
+//
+// Example:
+//
+//	type CreateUser struct {
+//	    Email string `json:"email"`
+//	}
+//
+//	func (c CreateUser) Validate() error {
+//	    if !strings.Contains(c.Email, "@") {
+//	        return errors.New("email is invalid")
+//	    }
+//	    return nil
+//	}
+//
+//	handler := JSONHandler(func(ctx context.Context, req CreateUser) (*User, error) {
+//	    return users.Create(ctx, req.Email)
+//	})
+func JSONHandler[Req, Resp any](fn func(context.Context, Req) (Resp, error)) HandlerFunc {
+	return JSONHandlerWith(JSONOptions{}, fn)
+}
+
+// JSONHandlerWith is JSONHandler with explicit options.
+func JSONHandlerWith[Req, Resp any](opts JSONOptions, fn func(context.Context, Req) (Resp, error)) HandlerFunc {
+	if opts.MaxBodyBytes <= 0 {
+		opts.MaxBodyBytes = 1 << 20
+	}
+	if opts.Status == 0 {
+		opts.Status = http.StatusOK
+	}
+	if opts.Encoders == nil {
+		opts.Encoders = map[string]Encoder{"application/json": encodeJSON}
+	}
+	if opts.Problem == nil {
+		opts.Problem = ProblemFor
+	}
+	offers := make([]string, 0, len(opts.Encoders))
+	for mediaType := range opts.Encoders {
+		offers = append(offers, mediaType)
+	}
+	sort.Strings(offers)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mediaType := NegotiateContentType(r.Header.Get("Accept"), offers...)
+		if mediaType == "" {
+			WriteProblem(w, NewProblem(http.StatusNotAcceptable, "supported media types: "+strings.Join(offers, ", ")))
+			return
+		}
+
+		var req Req
+		if err := decodeJSONBody(w, r, &req, opts); err != nil {
+			WriteProblem(w, err)
+			return
+		}
+		if v, ok := any(&req).(Validator); ok {
+			if err := v.Validate(); err != nil {
+				WriteProblem(w, validationProblem(err, opts.Problem))
+				return
+			}
+		}
+
+		resp, err := fn(r.Context(), req)
+		if err != nil {
+			WriteProblem(w, opts.Problem(err))
+			return
+		}
+		w.Header().Set("Content-Type", mediaType)
+		w.WriteHeader(opts.Status)
+		_ = opts.Encoders[mediaType](w, resp)
+	}
+}
+
+func encodeJSON(w io.Writer, v any) error {
+	return json.NewEncoder(w).Encode(v)
+}
+
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any, opts JSONOptions) *Problem {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil
+	}
+	if ct := r.Header.Get("Content-Type"); ct != "" {
+		mediaType, _, err := mime.ParseMediaType(ct)
+		if err != nil || (mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json")) {
+			return NewProblem(http.StatusUnsupportedMediaType, "request body must be application/json")
+		}
+	}
+
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, opts.MaxBodyBytes))
+	if !opts.AllowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+	err := dec.Decode(v)
+	if err == nil {
+		if dec.Decode(&struct{}{}) != io.EOF {
+			return NewProblem(http.StatusBadRequest, "request body must contain a single JSON value")
+		}
+		return nil
+	}
+
+	var maxErr *http.MaxBytesError
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.Is(err, io.EOF):
+		return nil
+	case errors.As(err, &maxErr):
+		return NewProblem(http.StatusRequestEntityTooLarge, "request body exceeds "+strconv.FormatInt(maxErr.Limit, 10)+" bytes")
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return NewProblem(http.StatusBadRequest, "request body is not valid JSON")
+	case errors.As(err, &typeErr):
+		return NewProblem(http.StatusBadRequest, "invalid value for field "+strconv.Quote(typeErr.Field))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		return NewProblem(http.StatusBadRequest, "unknown field "+strings.TrimPrefix(err.Error(), "json: unknown field "))
+	default:
+		return NewProblem(http.StatusBadRequest, "request body could not be decoded")
+	}
+}
+
+func validationProblem(err error, mapper func(error) *Problem) *Problem {
+	var p *Problem
+	var coded *CodedError
+	if errors.As(err, &p) || errors.As(err, &coded) {
+		return mapper(err)
+	}
+	return NewProblem(http.StatusUnprocessableEntity, err.Error())
+}
+
+// Problem is an RFC 9457 problem details object. It implements error so
+// handlers can return it directly.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are serialized as additional top-level members.
+	Extensions map[string]any
+}
+
+// NewProblem creates a problem with the standard title for status.
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Status: status, Title: http.StatusText(status), Detail: detail}
+}
+
+// Error implements the error interface.
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.Title + ": " + p.Detail
+	}
+	return p.Title
+}
+
+// MarshalJSON implements json.Marshaler.
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	m := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		m[k] = v
+	}
+	typ := p.Type
+	if typ == "" {
+		typ = "about:blank"
+	}
+	m["type"] = typ
+	if p.Title != "" {
+		m["title"] = p.Title
+	}
+	if p.Status != 0 {
+		m["status"] = p.Status
+	}
+	if p.Detail != "" {
+		m["detail"] = p.Detail
+	}
+	if p.Instance != "" {
+		m["instance"] = p.Instance
+	}
+	return json.Marshal(m)
+}
+
+// ProblemFor maps an error to problem details. *Problem errors are used as
+// is and *CodedError codes in the 4xx/5xx range become the status. Details
+// of other errors and of 5xx codes are not exposed to clients.
+func ProblemFor(err error) *Problem {
+	var p *Problem
+	if errors.As(err, &p) {
+		return p
+	}
+	var coded *CodedError
+	if errors.As(err, &coded) && coded.code >= 400 && coded.code < 600 {
+		if coded.code < 500 {
+			return NewProblem(coded.code, coded.msg)
+		}
+		return NewProblem(coded.code, "")
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return NewProblem(http.StatusGatewayTimeout, "")
+	}
+	return NewProblem(http.StatusInternalServerError, "")
+}
+
+// WriteProblem writes p as an application/problem+json response.
+func WriteProblem(w http.ResponseWriter, p *Problem) {
+	status := p.Status
+	if status == 0 {
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// NegotiateContentType picks the offer best matching an Accept header,
+// honoring q-values and wildcards. An empty header accepts the first offer.
+// It returns "" when nothing is acceptable.
+func NegotiateContentType(accept string, offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	if strings.TrimSpace(accept) == "" {
+		return offers[0]
+	}
+
+	best, bestQ, bestSpecificity := "", 0.0, -1
+	for _, offer := range offers {
+		offerType, offerSub, _ := strings.Cut(offer, "/")
+		q, specificity := 0.0, -1
+		for _, part := range strings.Split(accept, ",") {
+			mediaRange, params, _ := mime.ParseMediaType(strings.TrimSpace(part))
+			rangeType, rangeSub, _ := strings.Cut(mediaRange, "/")
+			s := -1
+			switch {
+			case rangeType == offerType && rangeSub == offerSub:
+				s = 2
+			case rangeType == offerType && rangeSub == "*":
+				s = 1
+			case rangeType == "*" && rangeSub == "*":
+				s = 0
+			}
+			if s <= specificity {
+				continue
+			}
+			specificity, q = s, 1.0
+			if v, ok := params["q"]; ok {
+				if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+					q = parsed
+				}
+			}
+		}
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = offer, q, specificity
+		}
+	}
+	return best
+}
@@ -0,0 +1,138 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// JSON Handler Tests
+// ============================================================================
+
+type greetRequest struct {
+	Name string `json:"name"`
+}
+
+func (g greetRequest) Validate() error {
+	if g.Name == "" {
+		return errors.New("name is required")
+	}
+	return nil
+}
+
+type greetResponse struct {
+	Message string `json:"message"`
+}
+
+func greetHandler() HandlerFunc {
+	return JSONHandlerWith(JSONOptions{MaxBodyBytes: 64}, func(ctx context.Context, req greetRequest) (greetResponse, error) {
+		switch req.Name {
+		case "ghost":
+			return greetResponse{}, ErrorFunc(func() string { return "no such user" }).WithCode(404)
+		case "db":
+			return greetResponse{}, errors.New("connection refused to 10.0.0.5")
+		}
+		return greetResponse{Message: "Hello, " + req.Name}, nil
+	})
+}
+
+func TestJSONHandler_Success(t *testing.T) {
+	req := httptest.NewRequest("POST", "/greet", strings.NewReader(`{"name":"Alice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	greetHandler().ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got '%s'", ct)
+	}
+	var resp greetResponse
+	json.NewDecoder(w.Body).Decode(&resp)
+	if resp.Message != "Hello, Alice" {
+		t.Errorf("expected 'Hello, Alice', got '%s'", resp.Message)
+	}
+}
+
+func TestJSONHandler_Problems(t *testing.T) {
+	tests := []struct {
+		name        string
+		body        string
+		contentType string
+		accept      string
+		status      int
+		detail      string
+	}{
+		{"malformed", `{"name":`, "application/json", "", 400, "request body is not valid JSON"},
+		{"unknown field", `{"name":"a","admin":true}`, "application/json", "", 400, `unknown field "admin"`},
+		{"trailing data", `{"name":"a"} {}`, "application/json", "", 400, "request body must contain a single JSON value"},
+		{"too large", `{"name":"` + strings.Repeat("a", 100) + `"}`, "application/json", "", 413, "request body exceeds 64 bytes"},
+		{"wrong content type", `name=a`, "application/x-www-form-urlencoded", "", 415, "request body must be application/json"},
+		{"validation", `{}`, "application/json", "", 422, "name is required"},
+		{"coded error", `{"name":"ghost"}`, "application/json", "", 404, "no such user"},
+		{"internal error", `{"name":"db"}`, "application/json", "", 500, ""},
+		{"not acceptable", `{"name":"a"}`, "application/json", "text/html", 406, "supported media types: application/json"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", "/greet", strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", tt.contentType)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		greetHandler().ServeHTTP(w, req)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.status, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+			t.Errorf("%s: expected problem+json, got '%s'", tt.name, ct)
+		}
+		var problem map[string]any
+		json.NewDecoder(w.Body).Decode(&problem)
+		if problem["status"] != float64(tt.status) || problem["title"] != http.StatusText(tt.status) {
+			t.Errorf("%s: unexpected problem %v", tt.name, problem)
+		}
+		if detail, _ := problem["detail"].(string); detail != tt.detail {
+			t.Errorf("%s: expected detail '%s', got '%s'", tt.name, tt.detail, detail)
+		}
+	}
+}
+
+func TestProblem_MarshalJSON(t *testing.T) {
+	p := NewProblem(http.StatusConflict, "balance too low")
+	p.Type = "https://example.com/probs/out-of-credit"
+	p.Extensions = map[string]any{"balance": 30}
+
+	data, _ := json.Marshal(p)
+	var m map[string]any
+	json.Unmarshal(data, &m)
+
+	if m["type"] != "https://example.com/probs/out-of-credit" || m["balance"] != float64(30) || m["status"] != float64(409) {
+		t.Errorf("unexpected problem JSON: %s", data)
+	}
+}
+
+func TestNegotiateContentType(t *testing.T) {
+	offers := []string{"application/json", "application/xml"}
+	tests := []struct {
+		accept, want string
+	}{
+		{"", "application/json"},
+		{"*/*", "application/json"},
+		{"application/xml", "application/xml"},
+		{"application/json;q=0.5, application/xml", "application/xml"},
+		{"application/*;q=0.2, application/xml;q=0", "application/json"},
+		{"text/html", ""},
+	}
+	for _, tt := range tests {
+		if got := NegotiateContentType(tt.accept, offers...); got != tt.want {
+			t.Errorf("Accept %q: expected '%s', got '%s'", tt.accept, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,483 @@
+package purefunccore
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math/big"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// JWT Verification
+// ============================================================================
+
+// JWT verification errors.
+var (
+	ErrJWTMalformed       = errors.New("jwt: malformed token")
+	ErrJWTMissingToken    = errors.New("jwt: missing token")
+	ErrJWTAlgorithm       = errors.New("jwt: algorithm not allowed")
+	ErrJWTSignature       = errors.New("jwt: invalid signature")
+	ErrJWTKeyNotFound     = errors.New("jwt: key not found")
+	ErrJWTUnsupportedKey  = errors.New("jwt: unsupported key")
+	ErrJWTExpired         = errors.New("jwt: token expired")
+	ErrJWTNotYetValid     = errors.New("jwt: token not yet valid")
+	ErrJWTIssuedInFuture  = errors.New("jwt: token issued in the future")
+	ErrJWTInvalidIssuer   = errors.New("jwt: invalid issuer")
+	ErrJWTInvalidAudience = errors.New("jwt: invalid audience")
+)
+
+var (
+	jwtBase64            = base64.RawURLEncoding
+	defaultJWTAlgorithms = []string{"HS256", "RS256", "ES256"}
+)
+
+// JWTKeyFunc resolves the verification key for a token header.
+// It returns []byte for HS256, *rsa.PublicKey for RS256 and
+// *ecdsa.PublicKey for ES256.
+type JWTKeyFunc func(kid, alg string) (any, error)
+
+// JWTConfig configures HandlerFunc.WithJWT.
+type JWTConfig struct {
+	// Keys resolves verification keys. Use (*JWKS).Lookup for key sets.
+	Keys JWTKeyFunc
+	// Algorithms lists accepted "alg" values. Defaults to HS256, RS256 and ES256.
+	Algorithms []string
+	// Issuer, when set, must equal the "iss" claim.
+	Issuer string
+	// Audience, when set, must appear in the "aud" claim.
+	Audience string
+	// Leeway is the allowed clock skew for exp, nbf and iat.
+	Leeway time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+	// Token extracts the raw token. Defaults to the Bearer Authorization header.
+	Token func(*http.Request) string
+}
+
+// JWTClaims holds the verified claims of a token.
+type JWTClaims struct {
+	Issuer    string
+	Subject   string
+	Audience  []string
+	ExpiresAt time.Time
+	NotBefore time.Time
+	IssuedAt  time.Time
+	ID        string
+	// Raw contains every claim, including the registered ones above.
+	Raw map[string]any
+}
+
+type jwtClaimsKey struct{}
+
+// JWTClaimsFromContext returns the claims stored by WithJWT, if any.
+func JWTClaimsFromContext(ctx context.Context) (*JWTClaims, bool) {
+	c, ok := ctx.Value(jwtClaimsKey{}).(*JWTClaims)
+	return c, ok
+}
+
+// WithJWT verifies a JWT on every request and stores its claims and a
+// Principal in the request context. Invalid tokens are rejected with 401.
+//
+// Example:
+//
+//	keys, _ := LoadJWKSFile("/etc/keys/jwks.json")
+//	handler := HandlerFunc(profile).
+//	    WithAuth(func(r *http.Request) bool {
+//	        p, _ := PrincipalFromContext(r.Context())
+//	        return p.Claims["role"] == "admin"
+//	    }).
+//	    WithJWT(JWTConfig{Keys: keys.Lookup, Issuer: "https://auth.example.com"})
+func (f HandlerFunc) WithJWT(cfg JWTConfig) HandlerFunc {
+	token := cfg.Token
+	if token == nil {
+		token = BearerToken
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		raw := token(r)
+		if raw == "" {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		claims, err := VerifyJWT(raw, cfg)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		ctx := context.WithValue(r.Context(), jwtClaimsKey{}, claims)
+		ctx = ContextWithPrincipal(ctx, &Principal{Subject: claims.Subject, Claims: claims.Raw})
+		f(w, r.WithContext(ctx))
+	}
+}
+
+// BearerToken extracts the token from an "Authorization: Bearer" header.
+func BearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// VerifyJWT parses a compact JWS token, verifies its signature and
+// validates its registered claims.
+func VerifyJWT(token string, cfg JWTConfig) (*JWTClaims, error) {
+	if token == "" {
+		return nil, ErrJWTMissingToken
+	}
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, ErrJWTMalformed
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	if err := decodeJWTSegment(parts[0], &header); err != nil {
+		return nil, err
+	}
+	allowed := cfg.Algorithms
+	if len(allowed) == 0 {
+		allowed = defaultJWTAlgorithms
+	}
+	if !slices.Contains(allowed, header.Alg) {
+		return nil, ErrJWTAlgorithm
+	}
+	if cfg.Keys == nil {
+		return nil, ErrJWTKeyNotFound
+	}
+	key, err := cfg.Keys(header.Kid, header.Alg)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := jwtBase64.DecodeString(parts[2])
+	if err != nil {
+		return nil, ErrJWTMalformed
+	}
+	if err := verifyJWTSignature(header.Alg, key, parts[0]+"."+parts[1], sig); err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	if err := decodeJWTSegment(parts[1], &raw); err != nil {
+		return nil, err
+	}
+	claims, err := parseJWTClaims(raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateJWTClaims(claims, cfg); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func decodeJWTSegment(seg string, v any) error {
+	data, err := jwtBase64.DecodeString(seg)
+	if err != nil {
+		return ErrJWTMalformed
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return ErrJWTMalformed
+	}
+	return nil
+}
+
+func verifyJWTSignature(alg string, key any, signingInput string, sig []byte) error {
+	digest := sha256.Sum256([]byte(signingInput))
+	switch alg {
+	case "HS256":
+		secret, ok := key.([]byte)
+		if !ok {
+			return ErrJWTUnsupportedKey
+		}
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+		if !hmac.Equal(mac.Sum(nil), sig) {
+			return ErrJWTSignature
+		}
+	case "RS256":
+		pub, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return ErrJWTUnsupportedKey
+		}
+		if rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], sig) != nil {
+			return ErrJWTSignature
+		}
+	case "ES256":
+		pub, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return ErrJWTUnsupportedKey
+		}
+		if len(sig) != 64 {
+			return ErrJWTSignature
+		}
+		r := new(big.Int).SetBytes(sig[:32])
+		s := new(big.Int).SetBytes(sig[32:])
+		if !ecdsa.Verify(pub, digest[:], r, s) {
+			return ErrJWTSignature
+		}
+	default:
+		return ErrJWTAlgorithm
+	}
+	return nil
+}
+
+func parseJWTClaims(raw map[string]any) (*JWTClaims, error) {
+	c := &JWTClaims{Raw: raw}
+	c.Issuer, _ = raw["iss"].(string)
+	c.Subject, _ = raw["sub"].(string)
+	c.ID, _ = raw["jti"].(string)
+
+	switch aud := raw["aud"].(type) {
+	case nil:
+	case string:
+		c.Audience = []string{aud}
+	case []any:
+		for _, a := range aud {
+			s, ok := a.(string)
+			if !ok {
+				return nil, ErrJWTMalformed
+			}
+			c.Audience = append(c.Audience, s)
+		}
+	default:
+		return nil, ErrJWTMalformed
+	}
+
+	for name, dst := range map[string]*time.Time{"exp": &c.ExpiresAt, "nbf": &c.NotBefore, "iat": &c.IssuedAt} {
+		switch v := raw[name].(type) {
+		case nil:
+		case float64:
+			sec := int64(v)
+			*dst = time.Unix(sec, int64((v-float64(sec))*float64(time.Second)))
+		default:
+			return nil, ErrJWTMalformed
+		}
+	}
+	return c, nil
+}
+
+func validateJWTClaims(c *JWTClaims, cfg JWTConfig) error {
+	now := time.Now()
+	if cfg.Now != nil {
+		now = cfg.Now()
+	}
+	if !c.ExpiresAt.IsZero() && !now.Before(c.ExpiresAt.Add(cfg.Leeway)) {
+		return ErrJWTExpired
+	}
+	if !c.NotBefore.IsZero() && now.Add(cfg.Leeway).Before(c.NotBefore) {
+		return ErrJWTNotYetValid
+	}
+	if !c.IssuedAt.IsZero() && now.Add(cfg.Leeway).Before(c.IssuedAt) {
+		return ErrJWTIssuedInFuture
+	}
+	if cfg.Issuer != "" && c.Issuer != cfg.Issuer {
+		return ErrJWTInvalidIssuer
+	}
+	if cfg.Audience != "" && !slices.Contains(c.Audience, cfg.Audience) {
+		return ErrJWTInvalidAudience
+	}
+	return nil
+}
+
+// JWKS is a local JSON Web Key Set that supports key rotation by kid.
+// It is safe for concurrent use; Reload swaps in a new set atomically.
+//
+// Example:
+//
+//	keys, err := LoadJWKS(os.DirFS("/etc/keys"), "jwks.json")
+//	handler = handler.WithJWT(JWTConfig{Keys: keys.Lookup})
+//
+//	// Later, after the file was rotated:
+//	err = keys.Reload()
+type JWKS struct {
+	mu     sync.RWMutex
+	keys   map[string]jwk
+	source func() ([]byte, error)
+}
+
+type jwk struct {
+	alg string
+	key any
+}
+
+// NewJWKS returns an empty key set. Keys are added with AddKey.
+func NewJWKS() *JWKS {
+	return &JWKS{keys: make(map[string]jwk)}
+}
+
+// ParseJWKS parses a JWKS document ({"keys": [...]}).
+func ParseJWKS(data []byte) (*JWKS, error) {
+	keys, err := parseJWKSKeys(data)
+	if err != nil {
+		return nil, err
+	}
+	return &JWKS{keys: keys}, nil
+}
+
+// LoadJWKS reads and parses a JWKS document from fsys.
+// The returned set can be refreshed from the same file with Reload.
+func LoadJWKS(fsys fs.FS, name string) (*JWKS, error) {
+	set := &JWKS{source: func() ([]byte, error) { return fs.ReadFile(fsys, name) }}
+	if err := set.Reload(); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// LoadJWKSFile reads and parses a JWKS document from the local filesystem.
+func LoadJWKSFile(path string) (*JWKS, error) {
+	return LoadJWKS(os.DirFS(filepath.Dir(path)), filepath.Base(path))
+}
+
+// Reload re-reads the key set from the source it was loaded from.
+// On error the current keys are kept.
+func (s *JWKS) Reload() error {
+	if s.source == nil {
+		return errors.New("jwt: key set has no source to reload from")
+	}
+	data, err := s.source()
+	if err != nil {
+		return fmt.Errorf("jwt: reading key set: %w", err)
+	}
+	keys, err := parseJWKSKeys(data)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	s.keys = keys
+	s.mu.Unlock()
+	return nil
+}
+
+// AddKey registers a key under kid. alg may be empty to allow any
+// algorithm compatible with the key type.
+func (s *JWKS) AddKey(kid, alg string, key any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.keys == nil {
+		s.keys = make(map[string]jwk)
+	}
+	s.keys[kid] = jwk{alg: alg, key: key}
+}
+
+// Lookup implements JWTKeyFunc. Tokens without a kid match only when the
+// set holds exactly one key.
+func (s *JWKS) Lookup(kid, alg string) (any, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	k, ok := s.keys[kid]
+	if !ok && kid == "" && len(s.keys) == 1 {
+		for _, only := range s.keys {
+			k, ok = only, true
+		}
+	}
+	if !ok {
+		return nil, ErrJWTKeyNotFound
+	}
+	if k.alg != "" && k.alg != alg {
+		return nil, ErrJWTAlgorithm
+	}
+	return k.key, nil
+}
+
+func parseJWKSKeys(data []byte) (map[string]jwk, error) {
+	var doc struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Alg string `json:"alg"`
+			Use string `json:"use"`
+			Crv string `json:"crv"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+			K   string `json:"k"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("jwt: parsing key set: %w", err)
+	}
+
+	// Keys of unsupported types or curves are skipped like encryption keys,
+	// so a provider publishing a new key type does not break verification.
+	keys := make(map[string]jwk, len(doc.Keys))
+	unsupported := 0
+	for _, k := range doc.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		var key any
+		switch k.Kty {
+		case "oct":
+			secret, err := jwtBase64.DecodeString(k.K)
+			if err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %w", k.Kid, err)
+			}
+			key = secret
+		case "RSA":
+			n, err1 := jwtBase64.DecodeString(k.N)
+			e, err2 := jwtBase64.DecodeString(k.E)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %w", k.Kid, err)
+			}
+			exp := new(big.Int).SetBytes(e)
+			if !exp.IsInt64() || exp.Int64() > 1<<31-1 {
+				unsupported++
+				continue
+			}
+			key = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exp.Int64())}
+		case "EC":
+			if k.Crv != "P-256" {
+				unsupported++
+				continue
+			}
+			x, err1 := jwtBase64.DecodeString(k.X)
+			y, err2 := jwtBase64.DecodeString(k.Y)
+			if err := errors.Join(err1, err2); err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %w", k.Kid, err)
+			}
+			if len(x) > 32 || len(y) > 32 {
+				unsupported++
+				continue
+			}
+			point := make([]byte, 65)
+			point[0] = 4
+			copy(point[33-len(x):33], x)
+			copy(point[65-len(y):], y)
+			pub, err := ecdsa.ParseUncompressedPublicKey(elliptic.P256(), point)
+			if err != nil {
+				return nil, fmt.Errorf("jwt: key %q: %w", k.Kid, err)
+			}
+			key = pub
+		default:
+			unsupported++
+			continue
+		}
+		keys[k.Kid] = jwk{alg: k.Alg, key: key}
+	}
+	if len(keys) == 0 && unsupported > 0 {
+		return nil, fmt.Errorf("jwt: no supported keys in key set: %w", ErrJWTUnsupportedKey)
+	}
+	return keys, nil
+}
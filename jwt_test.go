@@ -0,0 +1,277 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+// ============================================================================
+// JWT Tests
+// ============================================================================
+
+var jwtTestNow = time.Unix(1_700_000_000, 0)
+
+func signTestJWT(t *testing.T, alg, kid string, key any, claims map[string]any) string {
+	t.Helper()
+	header, _ := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	payload, _ := json.Marshal(claims)
+	input := jwtBase64.EncodeToString(header) + "." + jwtBase64.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(input))
+
+	var sig []byte
+	switch alg {
+	case "HS256":
+		mac := hmac.New(sha256.New, key.([]byte))
+		mac.Write([]byte(input))
+		sig = mac.Sum(nil)
+	case "RS256":
+		s, err := rsa.SignPKCS1v15(rand.Reader, key.(*rsa.PrivateKey), crypto.SHA256, digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = s
+	case "ES256":
+		r, s, err := ecdsa.Sign(rand.Reader, key.(*ecdsa.PrivateKey), digest[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		sig = make([]byte, 64)
+		r.FillBytes(sig[:32])
+		s.FillBytes(sig[32:])
+	}
+	return input + "." + jwtBase64.EncodeToString(sig)
+}
+
+func TestVerifyJWT_Algorithms(t *testing.T) {
+	secret := []byte("top-secret")
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	keys := NewJWKS()
+	keys.AddKey("hs", "HS256", secret)
+	keys.AddKey("rs", "RS256", &rsaKey.PublicKey)
+	keys.AddKey("es", "ES256", &ecKey.PublicKey)
+
+	cfg := JWTConfig{Keys: keys.Lookup, Now: func() time.Time { return jwtTestNow }}
+	claims := map[string]any{"sub": "alice", "exp": jwtTestNow.Add(time.Hour).Unix()}
+
+	tests := []struct {
+		alg, kid string
+		key      any
+	}{
+		{"HS256", "hs", secret},
+		{"RS256", "rs", rsaKey},
+		{"ES256", "es", ecKey},
+	}
+	for _, tt := range tests {
+		token := signTestJWT(t, tt.alg, tt.kid, tt.key, claims)
+		got, err := VerifyJWT(token, cfg)
+		if err != nil {
+			t.Errorf("%s: expected valid token, got %v", tt.alg, err)
+			continue
+		}
+		if got.Subject != "alice" {
+			t.Errorf("%s: expected subject 'alice', got '%s'", tt.alg, got.Subject)
+		}
+
+		tampered := token[:len(token)-4] + "AAAA"
+		if _, err := VerifyJWT(tampered, cfg); !errors.Is(err, ErrJWTSignature) {
+			t.Errorf("%s: expected signature error, got %v", tt.alg, err)
+		}
+	}
+}
+
+func TestVerifyJWT_RejectsNone(t *testing.T) {
+	header := jwtBase64.EncodeToString([]byte(`{"alg":"none"}`))
+	payload := jwtBase64.EncodeToString([]byte(`{"sub":"mallory"}`))
+	cfg := JWTConfig{Keys: func(kid, alg string) (any, error) { return nil, nil }}
+
+	if _, err := VerifyJWT(header+"."+payload+".", cfg); !errors.Is(err, ErrJWTAlgorithm) {
+		t.Errorf("expected algorithm error, got %v", err)
+	}
+}
+
+func TestVerifyJWT_Claims(t *testing.T) {
+	secret := []byte("secret")
+	keys := NewJWKS()
+	keys.AddKey("", "HS256", secret)
+	cfg := JWTConfig{
+		Keys:     keys.Lookup,
+		Issuer:   "https://auth.example.com",
+		Audience: "api",
+		Leeway:   30 * time.Second,
+		Now:      func() time.Time { return jwtTestNow },
+	}
+	base := func() map[string]any {
+		return map[string]any{
+			"iss": "https://auth.example.com",
+			"aud": []string{"web", "api"},
+			"exp": jwtTestNow.Add(time.Minute).Unix(),
+		}
+	}
+
+	tests := []struct {
+		name   string
+		mutate func(map[string]any)
+		want   error
+	}{
+		{"valid", func(map[string]any) {}, nil},
+		{"expired", func(c map[string]any) { c["exp"] = jwtTestNow.Add(-time.Minute).Unix() }, ErrJWTExpired},
+		{"expired within leeway", func(c map[string]any) { c["exp"] = jwtTestNow.Add(-10 * time.Second).Unix() }, nil},
+		{"not yet valid", func(c map[string]any) { c["nbf"] = jwtTestNow.Add(time.Minute).Unix() }, ErrJWTNotYetValid},
+		{"issued in future", func(c map[string]any) { c["iat"] = jwtTestNow.Add(time.Minute).Unix() }, ErrJWTIssuedInFuture},
+		{"wrong issuer", func(c map[string]any) { c["iss"] = "https://evil.example.com" }, ErrJWTInvalidIssuer},
+		{"wrong audience", func(c map[string]any) { c["aud"] = "web" }, ErrJWTInvalidAudience},
+	}
+	for _, tt := range tests {
+		claims := base()
+		tt.mutate(claims)
+		_, err := VerifyJWT(signTestJWT(t, "HS256", "", secret, claims), cfg)
+		if !errors.Is(err, tt.want) {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, err)
+		}
+	}
+}
+
+func TestLoadJWKS_Rotation(t *testing.T) {
+	ecKey1, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecKey2, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	ecJWK := func(kid string, k *ecdsa.PrivateKey) map[string]string {
+		point, _ := k.PublicKey.Bytes()
+		return map[string]string{
+			"kty": "EC", "crv": "P-256", "kid": kid, "alg": "ES256",
+			"x": jwtBase64.EncodeToString(point[1:33]),
+			"y": jwtBase64.EncodeToString(point[33:]),
+		}
+	}
+	rsaJWK := map[string]string{
+		"kty": "RSA", "kid": "rsa-1", "alg": "RS256",
+		"n": jwtBase64.EncodeToString(rsaKey.N.Bytes()),
+		"e": jwtBase64.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}
+	doc := func(keys ...map[string]string) []byte {
+		data, _ := json.Marshal(map[string]any{"keys": keys})
+		return data
+	}
+
+	fsys := fstest.MapFS{"jwks.json": {Data: doc(ecJWK("k1", ecKey1), rsaJWK)}}
+	keys, err := LoadJWKS(fsys, "jwks.json")
+	if err != nil {
+		t.Fatalf("expected key set to load, got %v", err)
+	}
+	cfg := JWTConfig{Keys: keys.Lookup}
+	claims := map[string]any{"sub": "svc"}
+
+	if _, err := VerifyJWT(signTestJWT(t, "RS256", "rsa-1", rsaKey, claims), cfg); err != nil {
+		t.Errorf("expected RSA token to verify, got %v", err)
+	}
+	if _, err := VerifyJWT(signTestJWT(t, "ES256", "k1", ecKey1, claims), cfg); err != nil {
+		t.Errorf("expected k1 token to verify, got %v", err)
+	}
+	if _, err := VerifyJWT(signTestJWT(t, "ES256", "k2", ecKey2, claims), cfg); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("expected unknown kid before rotation, got %v", err)
+	}
+
+	fsys["jwks.json"] = &fstest.MapFile{Data: doc(ecJWK("k2", ecKey2))}
+	if err := keys.Reload(); err != nil {
+		t.Fatalf("expected reload to succeed, got %v", err)
+	}
+	if _, err := VerifyJWT(signTestJWT(t, "ES256", "k2", ecKey2, claims), cfg); err != nil {
+		t.Errorf("expected k2 token to verify after rotation, got %v", err)
+	}
+	if _, err := VerifyJWT(signTestJWT(t, "ES256", "k1", ecKey1, claims), cfg); !errors.Is(err, ErrJWTKeyNotFound) {
+		t.Errorf("expected k1 to be retired after rotation, got %v", err)
+	}
+}
+
+func TestParseJWKS_SkipsUnsupportedKeys(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	point, _ := ecKey.PublicKey.Bytes()
+	unsupported := []map[string]string{
+		{"kty": "OKP", "crv": "Ed25519", "kid": "ed", "x": "11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"},
+		{"kty": "EC", "crv": "P-384", "kid": "p384", "x": "AA", "y": "AA"},
+		{"kty": "PQC", "kid": "future"},
+	}
+	supported := map[string]string{
+		"kty": "EC", "crv": "P-256", "kid": "k1", "alg": "ES256",
+		"x": jwtBase64.EncodeToString(point[1:33]),
+		"y": jwtBase64.EncodeToString(point[33:]),
+	}
+
+	data, _ := json.Marshal(map[string]any{"keys": append(unsupported, supported)})
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		t.Fatalf("expected mixed key set to load, got %v", err)
+	}
+	cfg := JWTConfig{Keys: keys.Lookup}
+	if _, err := VerifyJWT(signTestJWT(t, "ES256", "k1", ecKey, map[string]any{"sub": "svc"}), cfg); err != nil {
+		t.Errorf("expected supported key to verify, got %v", err)
+	}
+
+	data, _ = json.Marshal(map[string]any{"keys": unsupported})
+	if _, err := ParseJWKS(data); !errors.Is(err, ErrJWTUnsupportedKey) {
+		t.Errorf("expected error when no key is usable, got %v", err)
+	}
+}
+
+func TestHandlerFunc_WithJWT(t *testing.T) {
+	secret := []byte("secret")
+	var principal *Principal
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = PrincipalFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}).
+		WithAuth(func(r *http.Request) bool {
+			p, ok := PrincipalFromContext(r.Context())
+			return ok && p.Claims["role"] == "admin"
+		}).
+		WithJWT(JWTConfig{
+			Keys: func(kid, alg string) (any, error) { return secret, nil },
+			Now:  func() time.Time { return jwtTestNow },
+		})
+
+	// Missing token
+	req1 := httptest.NewRequest("GET", "/", nil)
+	w1 := httptest.NewRecorder()
+	handler.ServeHTTP(w1, req1)
+	if w1.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w1.Code)
+	}
+
+	// Valid token, wrong role
+	req2 := httptest.NewRequest("GET", "/", nil)
+	req2.Header.Set("Authorization", "Bearer "+signTestJWT(t, "HS256", "", secret, map[string]any{"sub": "bob"}))
+	w2 := httptest.NewRecorder()
+	handler.ServeHTTP(w2, req2)
+	if w2.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w2.Code)
+	}
+
+	// Valid token, admin role
+	req3 := httptest.NewRequest("GET", "/", nil)
+	req3.Header.Set("Authorization", "Bearer "+signTestJWT(t, "HS256", "", secret, map[string]any{"sub": "alice", "role": "admin"}))
+	w3 := httptest.NewRecorder()
+	handler.ServeHTTP(w3, req3)
+	if w3.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w3.Code)
+	}
+	if principal == nil || principal.Subject != "alice" {
+		t.Errorf("expected principal 'alice', got %+v", principal)
+	}
+}
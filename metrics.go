@@ -0,0 +1,233 @@
+package purefunccore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ============================================================================
+// HTTP Metrics
+// ============================================================================
+
+// DefaultLatencyBuckets are the histogram bounds, in seconds, used by
+// NewMetricsRegistry when none are given.
+var DefaultLatencyBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// sizeBuckets are the response size histogram bounds in bytes.
+var sizeBuckets = []float64{100, 1000, 10000, 100000, 1e6, 1e7}
+
+// MetricsRegistry holds HTTP server metrics recorded by WithMetrics and
+// exposes them in the Prometheus text format.
+type MetricsRegistry struct {
+	buckets  []float64
+	inFlight atomic.Int64
+
+	mu        sync.Mutex
+	requests  map[string]uint64
+	durations map[string]*histogram
+	sizes     map[string]*histogram
+}
+
+type histogram struct {
+	counts []uint64 // per bucket, not cumulative
+	sum    float64
+	count  uint64
+}
+
+func (h *histogram) observe(bounds []float64, v float64) {
+	if h.counts == nil {
+		h.counts = make([]uint64, len(bounds))
+	}
+	if i := sort.SearchFloat64s(bounds, v); i < len(bounds) {
+		h.counts[i]++
+	}
+	h.sum += v
+	h.count++
+}
+
+// DefaultMetricsRegistry is used by WithMetrics(nil) and MetricsHandler.
+var DefaultMetricsRegistry = NewMetricsRegistry()
+
+// NewMetricsRegistry creates a registry with the given latency buckets in
+// seconds, or DefaultLatencyBuckets if none are given.
+func NewMetricsRegistry(buckets ...float64) *MetricsRegistry {
+	if len(buckets) == 0 {
+		buckets = DefaultLatencyBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &MetricsRegistry{
+		buckets:   buckets,
+		requests:  make(map[string]uint64),
+		durations: make(map[string]*histogram),
+		sizes:     make(map[string]*histogram),
+	}
+}
+
+// routeKey carries a *routeHolder that a Router fills in with the matched
+// pattern, so WithMetrics sees it even when middleware in between replaces
+// the request.
+type routeKey struct{}
+
+type routeHolder struct{ pattern atomic.Pointer[string] }
+
+// recordRoute stores r.Pattern in the holder installed by WithMetrics.
+func recordRoute(h HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if holder, ok := r.Context().Value(routeKey{}).(*routeHolder); ok {
+			pattern := r.Pattern
+			holder.pattern.Store(&pattern)
+		}
+		h(w, r)
+	}
+}
+
+// WithMetrics records request counts by route, method and status class,
+// latency and response size histograms, and the number of in-flight
+// requests. The route label is the matched Router pattern rather than the
+// raw path, so cardinality stays bounded; unmatched requests are labelled
+// "unmatched". A Router reports its pattern through any middleware in
+// between; a plain ServeMux only does when it receives WithMetrics' request
+// directly. A nil registry uses DefaultMetricsRegistry.
+//
+// Example:
+//
+//	router.Get("/metrics", MetricsHandler())
+//	handler := HandlerFunc(router.ServeHTTP).WithMetrics(nil)
+func (f HandlerFunc) WithMetrics(registry *MetricsRegistry) HandlerFunc {
+	if registry == nil {
+		registry = DefaultMetricsRegistry
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		registry.inFlight.Add(1)
+		defer registry.inFlight.Add(-1)
+
+		start := time.Now()
+		holder := &routeHolder{}
+		r = r.WithContext(context.WithValue(r.Context(), routeKey{}, holder))
+		sw := newStatusWriter(w)
+		defer func() {
+			status := sw.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			route := r.Pattern
+			if p := holder.pattern.Load(); p != nil {
+				route = *p
+			}
+			registry.observe(r.Method, route, status, time.Since(start), sw.written)
+		}()
+		f(sw, r)
+	}
+}
+
+func (m *MetricsRegistry) observe(method, route string, status int, d time.Duration, size int64) {
+	if _, path, ok := strings.Cut(route, " "); ok {
+		route = strings.TrimLeft(path, " \t")
+	}
+	if route == "" {
+		route = "unmatched"
+	}
+	labels := `method="` + metricMethod(method) + `",route="` + escapeLabel(route) + `"`
+	class := strconv.Itoa(status/100) + "xx"
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.requests[labels+`,status="`+class+`"`]++
+	if m.durations[labels] == nil {
+		m.durations[labels] = &histogram{}
+		m.sizes[labels] = &histogram{}
+	}
+	m.durations[labels].observe(m.buckets, d.Seconds())
+	m.sizes[labels].observe(sizeBuckets, float64(size))
+}
+
+// metricMethod keeps the method label bounded when clients send arbitrary
+// methods.
+func metricMethod(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodOptions, http.MethodConnect, http.MethodTrace:
+		return method
+	}
+	return "OTHER"
+}
+
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format.
+func (m *MetricsRegistry) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	m.mu.Lock()
+	b.WriteString("# HELP http_requests_total Total HTTP requests by route, method and status class.\n")
+	b.WriteString("# TYPE http_requests_total counter\n")
+	for _, labels := range sortedKeys(m.requests) {
+		fmt.Fprintf(&b, "http_requests_total{%s} %d\n", labels, m.requests[labels])
+	}
+	writeHistograms(&b, "http_request_duration_seconds", "HTTP request latency in seconds.", m.buckets, m.durations)
+	writeHistograms(&b, "http_response_size_bytes", "HTTP response body size in bytes.", sizeBuckets, m.sizes)
+	m.mu.Unlock()
+
+	b.WriteString("# HELP http_requests_in_flight HTTP requests currently being served.\n")
+	b.WriteString("# TYPE http_requests_in_flight gauge\n")
+	fmt.Fprintf(&b, "http_requests_in_flight %d\n", m.inFlight.Load())
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+func writeHistograms(b *strings.Builder, name, help string, bounds []float64, hists map[string]*histogram) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s histogram\n", name, help, name)
+	for _, labels := range sortedKeys(hists) {
+		h := hists[labels]
+		var cumulative uint64
+		for i, bound := range bounds {
+			cumulative += h.counts[i]
+			fmt.Fprintf(b, "%s_bucket{%s,le=\"%s\"} %d\n", name, labels, formatFloat(bound), cumulative)
+		}
+		fmt.Fprintf(b, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, h.count)
+		fmt.Fprintf(b, "%s_sum{%s} %s\n", name, labels, formatFloat(h.sum))
+		fmt.Fprintf(b, "%s_count{%s} %d\n", name, labels, h.count)
+	}
+}
+
+func formatFloat(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Handler serves the registry in the Prometheus text format.
+func (m *MetricsRegistry) Handler() HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_, _ = m.WriteTo(w)
+	}
+}
+
+// MetricsHandler serves DefaultMetricsRegistry in the Prometheus text format.
+func MetricsHandler() HandlerFunc {
+	return DefaultMetricsRegistry.Handler()
+}
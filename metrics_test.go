@@ -0,0 +1,90 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Metrics Tests
+// ============================================================================
+
+func TestHandlerFunc_WithMetrics(t *testing.T) {
+	registry := NewMetricsRegistry(0.1, 1)
+	router := NewRouter()
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "user")
+	})
+	router.Post("/users", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	handler := HandlerFunc(router.ServeHTTP).WithMetrics(registry)
+
+	for _, target := range []string{"GET /users/1", "GET /users/2", "POST /users", "GET /missing", "BREW /users/1"} {
+		method, path, _ := strings.Cut(target, " ")
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, nil))
+	}
+
+	w := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	out := w.Body.String()
+
+	for _, want := range []string{
+		`http_requests_total{method="GET",route="/users/{id}",status="2xx"} 2`,
+		`http_requests_total{method="POST",route="/users",status="2xx"} 1`,
+		`http_requests_total{method="GET",route="unmatched",status="4xx"} 1`,
+		`http_requests_total{method="OTHER",route="unmatched",status="4xx"} 1`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="0.1"} 2`,
+		`http_request_duration_seconds_bucket{method="GET",route="/users/{id}",le="+Inf"} 2`,
+		`http_request_duration_seconds_count{method="GET",route="/users/{id}"} 2`,
+		`http_response_size_bytes_sum{method="GET",route="/users/{id}"} 8`,
+		"# TYPE http_request_duration_seconds histogram",
+		"http_requests_in_flight 0",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", want, out)
+		}
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("expected Prometheus content type, got '%s'", ct)
+	}
+}
+
+func TestHandlerFunc_WithMetrics_InFlight(t *testing.T) {
+	registry := NewMetricsRegistry()
+	var during string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var b strings.Builder
+		registry.WriteTo(&b)
+		during = b.String()
+	}).WithMetrics(registry)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if !strings.Contains(during, "http_requests_in_flight 1") {
+		t.Errorf("expected one request in flight, got:\n%s", during)
+	}
+}
+
+func TestHandlerFunc_WithMetrics_RouteThroughMiddleware(t *testing.T) {
+	registry := NewMetricsRegistry()
+	router := NewRouter()
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {})
+	handler := HandlerFunc(router.ServeHTTP).
+		WithRequestID(RequestIDOptions{}).
+		WithTimeout(time.Second).
+		WithMetrics(registry)
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users/1", nil))
+
+	var b strings.Builder
+	registry.WriteTo(&b)
+	want := `http_requests_total{method="GET",route="/users/{id}",status="2xx"} 1`
+	if !strings.Contains(b.String(), want) {
+		t.Errorf("expected metrics to contain %q, got:\n%s", want, b.String())
+	}
+}
@@ -0,0 +1,121 @@
+package purefunccore
+
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// ============================================================================
+// Middleware
+// ============================================================================
+
+// Middleware is a reusable HandlerFunc decorator.
+// Method expressions such as HandlerFunc.Recover are Middleware too.
+//
+// Example:
+//
+//	standard := Chain(
+//	    HandlerFunc.Recover,
+//	    func(h HandlerFunc) HandlerFunc { return h.WithCORS("*") },
+//	    func(h HandlerFunc) HandlerFunc { return h.WithLogging(log.Println) },
+//	)
+//	secure := standard.Compose(func(h HandlerFunc) HandlerFunc {
+//	    return h.WithAuth(auth).WithTimeout(10 * time.Second)
+//	})
+//
+//	http.Handle("/public", standard.Then(handlePublic))
+//	http.Handle("/secure", secure.Then(handleSecure))
+type Middleware func(HandlerFunc) HandlerFunc
+
+// Then applies the middleware to a handler.
+func (m Middleware) Then(h HandlerFunc) HandlerFunc {
+	return m(h)
+}
+
+// Empty returns middleware that leaves handlers unchanged (Monoid identity).
+func (m Middleware) Empty() Middleware {
+	return func(h HandlerFunc) HandlerFunc { return h }
+}
+
+// Compose runs this middleware around next (Monoid operation).
+func (m Middleware) Compose(next Middleware) Middleware {
+	return func(h HandlerFunc) HandlerFunc {
+		return m(next(h))
+	}
+}
+
+// Chain composes middleware so that the first one is the outermost.
+func Chain(middleware ...Middleware) Middleware {
+	return func(h HandlerFunc) HandlerFunc {
+		for _, m := range slices.Backward(middleware) {
+			h = m(h)
+		}
+		return h
+	}
+}
+
+// When applies the middleware only to requests matching the predicate.
+func (m Middleware) When(predicate func(*http.Request) bool) Middleware {
+	return func(h HandlerFunc) HandlerFunc {
+		wrapped := m(h)
+		return func(w http.ResponseWriter, r *http.Request) {
+			if predicate(r) {
+				wrapped(w, r)
+				return
+			}
+			h(w, r)
+		}
+	}
+}
+
+// Unless skips the middleware for request paths starting with pathPrefix.
+func (m Middleware) Unless(pathPrefix string) Middleware {
+	return m.When(func(r *http.Request) bool {
+		return !strings.HasPrefix(r.URL.Path, pathPrefix)
+	})
+}
+
+// Std converts the middleware to the func(http.Handler) http.Handler form
+// used by most third-party packages.
+func (m Middleware) Std() func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return m(next.ServeHTTP)
+	}
+}
+
+// FromStd adapts func(http.Handler) http.Handler middleware.
+func FromStd(mw func(http.Handler) http.Handler) Middleware {
+	return func(h HandlerFunc) HandlerFunc {
+		return mw(h).ServeHTTP
+	}
+}
+
+// MethodIs matches requests using one of the given methods.
+func MethodIs(methods ...string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		return slices.Contains(methods, r.Method)
+	}
+}
+
+// PathPrefix matches requests whose path starts with prefix.
+func PathPrefix(prefix string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		return strings.HasPrefix(r.URL.Path, prefix)
+	}
+}
+
+// HeaderIs matches requests whose header has the given value.
+func HeaderIs(name, value string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		return r.Header.Get(name) == value
+	}
+}
+
+// CookieIs matches requests carrying a cookie with the given value.
+func CookieIs(name, value string) func(*http.Request) bool {
+	return func(r *http.Request) bool {
+		c, err := r.Cookie(name)
+		return err == nil && c.Value == value
+	}
+}
@@ -0,0 +1,102 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================================================
+// Middleware Tests
+// ============================================================================
+
+func tagMiddleware(order *[]string, name string) Middleware {
+	return func(h HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			*order = append(*order, name)
+			h(w, r)
+		}
+	}
+}
+
+func TestChain_Order(t *testing.T) {
+	var order []string
+	handler := Chain(
+		tagMiddleware(&order, "a"),
+		tagMiddleware(&order, "b"),
+	).Compose(tagMiddleware(&order, "c")).Then(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if fmt.Sprint(order) != "[a b c handler]" {
+		t.Errorf("expected [a b c handler], got %v", order)
+	}
+}
+
+func TestMiddleware_Empty(t *testing.T) {
+	var order []string
+	m := tagMiddleware(&order, "a")
+	handler := m.Empty().Compose(m).Compose(m.Empty()).Then(HandlerFunc(nil).Empty())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	if fmt.Sprint(order) != "[a]" {
+		t.Errorf("expected identity to add nothing, got %v", order)
+	}
+}
+
+func TestMiddleware_WhenAndUnless(t *testing.T) {
+	var order []string
+	m := tagMiddleware(&order, "m")
+	handler := Chain(
+		m.When(MethodIs("POST")),
+		m.Unless("/health"),
+	).Then(HandlerFunc(nil).Empty())
+
+	tests := []struct {
+		method, path string
+		want         string
+	}{
+		{"GET", "/health", "[]"},
+		{"GET", "/users", "[m]"},
+		{"POST", "/users", "[m m]"},
+		{"POST", "/health", "[m]"},
+	}
+	for _, tt := range tests {
+		order = []string{}
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+		if fmt.Sprint(order) != tt.want {
+			t.Errorf("%s %s: expected %s, got %v", tt.method, tt.path, tt.want, order)
+		}
+	}
+}
+
+func TestFromStd(t *testing.T) {
+	std := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Std", "yes")
+			next.ServeHTTP(w, r)
+		})
+	}
+	handler := FromStd(std).Then(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusTeapot || w.Header().Get("X-Std") != "yes" {
+		t.Errorf("expected adapted middleware to run, got %d %q", w.Code, w.Header().Get("X-Std"))
+	}
+
+	// Round trip back to the standard form
+	w2 := httptest.NewRecorder()
+	FromStd(std).Std()(http.NotFoundHandler()).ServeHTTP(w2, httptest.NewRequest("GET", "/", nil))
+	if w2.Code != http.StatusNotFound || w2.Header().Get("X-Std") != "yes" {
+		t.Errorf("expected Std to preserve behavior, got %d %q", w2.Code, w2.Header().Get("X-Std"))
+	}
+}
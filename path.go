@@ -0,0 +1,122 @@
+package purefunccore
+
+import (
+	"net/http"
+	"path"
+	"strings"
+)
+
+// ============================================================================
+// Path Normalization and Method Override
+// ============================================================================
+
+// TrailingSlash is a trailing-slash policy for WithPathNormalization.
+type TrailingSlash int
+
+const (
+	// TrailingSlashKeep leaves trailing slashes as requested.
+	TrailingSlashKeep TrailingSlash = iota
+	// TrailingSlashStrip removes trailing slashes, so "/users/" becomes "/users".
+	TrailingSlashStrip
+	// TrailingSlashAdd appends a trailing slash, so "/users" becomes "/users/".
+	TrailingSlashAdd
+)
+
+// PathOptions configures HandlerFunc.WithPathNormalization.
+type PathOptions struct {
+	TrailingSlash TrailingSlash
+	// Lowercase folds the path to lower case.
+	Lowercase bool
+	// Rewrite serves the normalized path directly instead of redirecting.
+	Rewrite bool
+}
+
+// WithPathNormalization cleans request paths, resolving "." and ".."
+// segments and duplicate slashes, applies the trailing-slash policy and
+// optionally lowercases. Requests for a non-canonical path are redirected
+// to the canonical one with 301 for GET and HEAD, or 308 otherwise so the
+// method and body are preserved. The query string is kept.
+//
+// Example:
+//
+//	handler := HandlerFunc(router.ServeHTTP).
+//	    WithPathNormalization(PathOptions{TrailingSlash: TrailingSlashStrip})
+func (f HandlerFunc) WithPathNormalization(opts PathOptions) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		canonical := normalizePath(r.URL.Path, opts)
+		if canonical == r.URL.Path {
+			f(w, r)
+			return
+		}
+
+		u := *r.URL
+		u.Path = canonical
+		u.RawPath = ""
+		if opts.Rewrite {
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.URL = &u
+			f(w, r2)
+			return
+		}
+
+		code := http.StatusPermanentRedirect
+		if r.Method == http.MethodGet || r.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, r, u.RequestURI(), code)
+	}
+}
+
+func normalizePath(p string, opts PathOptions) string {
+	if p == "" {
+		return "/"
+	}
+	cleaned := path.Clean("/" + p)
+	if cleaned != "/" {
+		switch opts.TrailingSlash {
+		case TrailingSlashKeep:
+			if strings.HasSuffix(p, "/") {
+				cleaned += "/"
+			}
+		case TrailingSlashAdd:
+			cleaned += "/"
+		}
+	}
+	if opts.Lowercase {
+		cleaned = strings.ToLower(cleaned)
+	}
+	return cleaned
+}
+
+// WithMethodOverride lets POST requests tunnel PUT, PATCH and DELETE through
+// the X-HTTP-Method-Override header or, for HTML forms, a "_method" field.
+// Other methods are never overridden. Because forms can be submitted
+// cross-site, combine this with WithCSRF so only trusted forms are honored.
+//
+// Example:
+//
+//	handler := HandlerFunc(router.ServeHTTP).
+//	    WithMethodOverride().
+//	    WithCSRF(CSRFOptions{Secure: true})
+func (f HandlerFunc) WithMethodOverride() HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			f(w, r)
+			return
+		}
+		method := r.Header.Get("X-HTTP-Method-Override")
+		if method == "" && isFormContentType(r.Header.Get("Content-Type")) {
+			method = r.PostFormValue("_method")
+		}
+		switch method = strings.ToUpper(method); method {
+		case http.MethodPut, http.MethodPatch, http.MethodDelete:
+			r2 := new(http.Request)
+			*r2 = *r
+			r2.Method = method
+			f(w, r2)
+		default:
+			f(w, r)
+		}
+	}
+}
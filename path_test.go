@@ -0,0 +1,93 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Path Normalization and Method Override Tests
+// ============================================================================
+
+func TestHandlerFunc_WithPathNormalization(t *testing.T) {
+	tests := []struct {
+		opts     PathOptions
+		method   string
+		target   string
+		code     int
+		location string
+	}{
+		{PathOptions{}, "GET", "/users", http.StatusOK, ""},
+		{PathOptions{}, "GET", "/users/", http.StatusOK, ""},
+		{PathOptions{}, "GET", "/a//b/../users?page=2", http.StatusMovedPermanently, "/a/users?page=2"},
+		{PathOptions{TrailingSlash: TrailingSlashStrip}, "GET", "/users/", http.StatusMovedPermanently, "/users"},
+		{PathOptions{TrailingSlash: TrailingSlashStrip}, "GET", "/", http.StatusOK, ""},
+		{PathOptions{TrailingSlash: TrailingSlashStrip}, "POST", "/users/", http.StatusPermanentRedirect, "/users"},
+		{PathOptions{TrailingSlash: TrailingSlashAdd}, "GET", "/users", http.StatusMovedPermanently, "/users/"},
+		{PathOptions{Lowercase: true}, "GET", "/Users/Alice", http.StatusMovedPermanently, "/users/alice"},
+	}
+	for _, tt := range tests {
+		handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}).WithPathNormalization(tt.opts)
+		req := httptest.NewRequest(tt.method, "/", nil)
+		req.URL.Path, req.URL.RawQuery, _ = strings.Cut(tt.target, "?")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.target, tt.code, w.Code)
+		}
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%s %s: expected Location '%s', got '%s'", tt.method, tt.target, tt.location, got)
+		}
+	}
+}
+
+func TestHandlerFunc_WithPathNormalization_Rewrite(t *testing.T) {
+	var seen string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.URL.Path
+	}).WithPathNormalization(PathOptions{TrailingSlash: TrailingSlashStrip, Rewrite: true})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.URL.Path = "//users/"
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || seen != "/users" {
+		t.Errorf("expected rewrite to /users, got %d '%s'", w.Code, seen)
+	}
+}
+
+func TestHandlerFunc_WithMethodOverride(t *testing.T) {
+	var seen string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r.Method
+	}).WithMethodOverride()
+
+	tests := []struct {
+		method, header, form, expected string
+	}{
+		{"POST", "DELETE", "", "DELETE"},
+		{"POST", "patch", "", "PATCH"},
+		{"POST", "", "_method=PUT", "PUT"},
+		{"POST", "GET", "", "POST"},
+		{"GET", "DELETE", "", "GET"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/", strings.NewReader(tt.form))
+		if tt.header != "" {
+			req.Header.Set("X-HTTP-Method-Override", tt.header)
+		}
+		if tt.form != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if seen != tt.expected {
+			t.Errorf("%s with %q/%q: expected %s, got %s", tt.method, tt.header, tt.form, tt.expected, seen)
+		}
+	}
+}
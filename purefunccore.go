@@ -86,6 +86,7 @@
 package purefunccore
 
 import (
+	"bytes"
 	"context"
 	"database/sql/driver"
 	"errors"
@@ -93,6 +94,7 @@ import (
 	"io"
 	"io/fs"
 	"net/http"
+	"net/url"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -334,6 +336,19 @@ func (f CloseFunc) Close() error {
 	return f()
 }
 
+// Empty returns a closer that does nothing (Monoid identity).
+func (f CloseFunc) Empty() CloseFunc {
+	return func() error { return nil }
+}
+
+// Compose creates a closer that closes this, then next, even if this fails
+// (Monoid operation). Errors from both are joined.
+func (f CloseFunc) Compose(next CloseFunc) CloseFunc {
+	return func() error {
+		return errors.Join(f(), next())
+	}
+}
+
 // SeekFunc is a functional binding for io.Seeker.
 type SeekFunc func(offset int64, whence int) (int64, error)
 
@@ -445,6 +460,122 @@ func (f HandlerFunc) WithLogging(logger func(string)) HandlerFunc {
 	}
 }
 
+// statusWriter wraps http.ResponseWriter to record the status code and the
+// number of body bytes written. It passes Flush through and supports
+// http.ResponseController via Unwrap, so decorators using it compose in any order.
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	written     int64
+	wroteHeader bool
+}
+
+func newStatusWriter(w http.ResponseWriter) *statusWriter {
+	return &statusWriter{ResponseWriter: w}
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.wroteHeader {
+		return
+	}
+	// Informational responses may precede the final status.
+	if code >= 100 && code < 200 && code != http.StatusSwitchingProtocols {
+		sw.ResponseWriter.WriteHeader(code)
+		return
+	}
+	sw.status = code
+	sw.wroteHeader = true
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(b []byte) (int, error) {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	n, err := sw.ResponseWriter.Write(b)
+	sw.written += int64(n)
+	return n, err
+}
+
+func (sw *statusWriter) Flush() {
+	if !sw.wroteHeader {
+		sw.WriteHeader(http.StatusOK)
+	}
+	_ = http.NewResponseController(sw.ResponseWriter).Flush()
+}
+
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// responseBuffer captures a complete response so that decorators can inspect,
+// store or replay it before anything reaches the client. Headers set by the
+// handler are kept separately from those already on the underlying writer.
+// A Flush switches it to streaming, sending what was buffered so far.
+type responseBuffer struct {
+	w         http.ResponseWriter
+	header    http.Header
+	status    int
+	body      bytes.Buffer
+	streaming bool
+}
+
+func newResponseBuffer(w http.ResponseWriter) *responseBuffer {
+	return &responseBuffer{w: w, header: make(http.Header)}
+}
+
+func (rb *responseBuffer) Header() http.Header {
+	if rb.streaming {
+		return rb.w.Header()
+	}
+	return rb.header
+}
+
+func (rb *responseBuffer) WriteHeader(code int) {
+	if rb.streaming {
+		rb.w.WriteHeader(code)
+		return
+	}
+	if rb.status == 0 && (code < 100 || code >= 200) {
+		rb.status = code
+	}
+}
+
+func (rb *responseBuffer) Write(p []byte) (int, error) {
+	if rb.streaming {
+		return rb.w.Write(p)
+	}
+	if rb.status == 0 {
+		rb.status = http.StatusOK
+	}
+	return rb.body.Write(p)
+}
+
+func (rb *responseBuffer) Flush() {
+	if !rb.streaming {
+		rb.writeTo(rb.w)
+		rb.streaming = true
+	}
+	_ = http.NewResponseController(rb.w).Flush()
+}
+
+func (rb *responseBuffer) Unwrap() http.ResponseWriter {
+	return rb.w
+}
+
+// writeTo sends the buffered response to w.
+func (rb *responseBuffer) writeTo(w http.ResponseWriter) {
+	for k, v := range rb.header {
+		w.Header()[k] = v
+	}
+	status := rb.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	w.WriteHeader(status)
+	_, _ = w.Write(rb.body.Bytes())
+}
+
 // timeoutWriter wraps http.ResponseWriter to prevent concurrent writes
 type timeoutWriter struct {
 	http.ResponseWriter
@@ -459,9 +590,26 @@ func (tw *timeoutWriter) Write(b []byte) (int, error) {
 	}
 	tw.mu.Lock()
 	defer tw.mu.Unlock()
+	tw.wroteHeader = true
 	return tw.ResponseWriter.Write(b)
 }
 
+// Flush sends buffered data to the client so that streaming responses work
+// under WithTimeout.
+func (tw *timeoutWriter) Flush() {
+	if atomic.LoadInt32(&tw.timedOut) == 1 {
+		return
+	}
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	tw.wroteHeader = true
+	_ = http.NewResponseController(tw.ResponseWriter).Flush()
+}
+
+func (tw *timeoutWriter) Unwrap() http.ResponseWriter {
+	return tw.ResponseWriter
+}
+
 func (tw *timeoutWriter) WriteHeader(code int) {
 	if atomic.LoadInt32(&tw.timedOut) == 1 {
 		return
@@ -521,6 +669,27 @@ func (f HandlerFunc) WithAuth(authenticate func(*http.Request) bool) HandlerFunc
 	}
 }
 
+// Principal identifies the authenticated caller of a request.
+// Authentication decorators such as WithJWT store it in the request context
+// so that WithAuth predicates and handlers can inspect it.
+type Principal struct {
+	Subject string
+	Claims  map[string]any
+}
+
+type principalKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the principal.
+func ContextWithPrincipal(ctx context.Context, p *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(*Principal)
+	return p, ok && p != nil
+}
+
 // WithCORS adds CORS headers to the handler.
 func (f HandlerFunc) WithCORS(origin string) HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -538,15 +707,9 @@ func (f HandlerFunc) WithCORS(origin string) HandlerFunc {
 }
 
 // Recover adds panic recovery to the handler.
+// It replies with a generic 500; use RecoverWith to report panics.
 func (f HandlerFunc) Recover() HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		defer func() {
-			if err := recover(); err != nil {
-				http.Error(w, fmt.Sprintf("Internal Server Error: %v", err), http.StatusInternalServerError)
-			}
-		}()
-		f(w, r)
-	}
+	return f.RecoverWith(RecoverOptions{})
 }
 
 // RoundTripperFunc is a functional binding for http.RoundTripper.
@@ -557,6 +720,144 @@ func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return f(req)
 }
 
+// Empty returns a transport that sends requests with http.DefaultTransport.
+func (f RoundTripperFunc) Empty() RoundTripperFunc {
+	return http.DefaultTransport.RoundTrip
+}
+
+// Compose creates a transport that falls back to next when this one fails
+// with an error. The request body is rewound with GetBody; requests whose
+// body cannot be rewound are not retried.
+func (f RoundTripperFunc) Compose(next RoundTripperFunc) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := f(req)
+		if err == nil {
+			return resp, nil
+		}
+		retry, rerr := rewindRequest(req)
+		if rerr != nil {
+			return nil, err
+		}
+		return next(retry)
+	}
+}
+
+// rewindRequest returns a copy of req with a fresh body from GetBody.
+func rewindRequest(req *http.Request) (*http.Request, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	if req.GetBody == nil {
+		return nil, errors.New("request body cannot be rewound")
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+	r2 := req.Clone(req.Context())
+	r2.Body = body
+	return r2, nil
+}
+
+// Before runs a function on a copy of the request before it is sent, so
+// the caller's request is never modified.
+func (f RoundTripperFunc) Before(before func(*http.Request)) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		r2 := req.Clone(req.Context())
+		before(r2)
+		return f(r2)
+	}
+}
+
+// After runs a function with the response or error once the transport returns.
+func (f RoundTripperFunc) After(after func(*http.Response, error)) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		resp, err := f(req)
+		after(resp, err)
+		return resp, err
+	}
+}
+
+// WithHeader sets a header on every outgoing request, replacing any value
+// the caller set.
+func (f RoundTripperFunc) WithHeader(key, value string) RoundTripperFunc {
+	return f.Before(func(req *http.Request) {
+		req.Header.Set(key, value)
+	})
+}
+
+// WithUserAgent sets the User-Agent of outgoing requests.
+func (f RoundTripperFunc) WithUserAgent(userAgent string) RoundTripperFunc {
+	return f.WithHeader("User-Agent", userAgent)
+}
+
+// WithBaseURL resolves requests without a host against base, joining the
+// paths, so clients can be written with relative URLs like "/users".
+// It panics if base is not a valid absolute URL.
+func (f RoundTripperFunc) WithBaseURL(base string) RoundTripperFunc {
+	baseURL, err := url.Parse(base)
+	if err != nil || baseURL.Scheme == "" || baseURL.Host == "" {
+		panic(fmt.Sprintf("purefunccore: invalid base URL %q", base))
+	}
+	return func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host != "" {
+			return f(req)
+		}
+		r2 := req.Clone(req.Context())
+		r2.URL = baseURL.JoinPath(req.URL.Path)
+		r2.URL.RawQuery = req.URL.RawQuery
+		r2.URL.Fragment = ""
+		r2.Host = ""
+		return f(r2)
+	}
+}
+
+// WithLogging logs each outgoing request and its outcome.
+func (f RoundTripperFunc) WithLogging(logger func(string)) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		logger(fmt.Sprintf("Request: %s %s", req.Method, req.URL))
+		start := time.Now()
+		resp, err := f(req)
+		elapsed := time.Since(start).Round(time.Millisecond)
+		if err != nil {
+			logger(fmt.Sprintf("Failed: %s %s after %s: %v", req.Method, req.URL, elapsed, err))
+			return nil, err
+		}
+		logger(fmt.Sprintf("Completed: %s %s %d in %s", req.Method, req.URL, resp.StatusCode, elapsed))
+		return resp, nil
+	}
+}
+
+// WithTimeout bounds each request, including reading the response body.
+// The deadline is released when the body is closed.
+func (f RoundTripperFunc) WithTimeout(timeout time.Duration) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		resp, err := f(req.WithContext(ctx))
+		if err != nil {
+			cancel()
+			return nil, err
+		}
+		if resp.Body == nil {
+			resp.Body = http.NoBody
+		}
+		resp.Body = cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+		return resp, nil
+	}
+}
+
+// cancelOnClose releases a request context once the body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c cancelOnClose) Close() error {
+	err := c.ReadCloser.Close()
+	c.cancel()
+	return err
+}
+
 // ============================================================================
 // Context Package Bindings
 // ============================================================================
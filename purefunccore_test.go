@@ -3,6 +3,7 @@ package purefunccore
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -397,6 +398,109 @@ func TestHandlerFunc_Recover(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// RoundTripperFunc Tests
+// ============================================================================
+
+func okTransport(seen **http.Request) RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		*seen = req
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader("ok")), Request: req}, nil
+	}
+}
+
+func TestRoundTripperFunc_Decorators(t *testing.T) {
+	var seen *http.Request
+	var logs []string
+	var afterStatus int
+	client := &http.Client{Transport: okTransport(&seen).
+		WithUserAgent("purefunccore-test/1.0").
+		WithHeader("X-Tenant", "acme").
+		After(func(resp *http.Response, err error) { afterStatus = resp.StatusCode }).
+		WithLogging(func(msg string) { logs = append(logs, msg) }).
+		WithBaseURL("https://api.example.com/v1/")}
+
+	req, _ := http.NewRequest("GET", "/users?page=2", nil)
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	resp.Body.Close()
+
+	if seen.URL.String() != "https://api.example.com/v1/users?page=2" {
+		t.Errorf("expected base URL joined, got '%s'", seen.URL)
+	}
+	if seen.Header.Get("User-Agent") != "purefunccore-test/1.0" || seen.Header.Get("X-Tenant") != "acme" {
+		t.Errorf("expected headers set, got %v", seen.Header)
+	}
+	if req.Header.Get("X-Tenant") != "" {
+		t.Error("expected caller's request to be left unmodified")
+	}
+	if afterStatus != http.StatusOK {
+		t.Errorf("expected After to see 200, got %d", afterStatus)
+	}
+	if len(logs) != 2 || !strings.HasPrefix(logs[0], "Request: GET https://api.example.com/v1/users") ||
+		!strings.HasPrefix(logs[1], "Completed: GET") {
+		t.Errorf("unexpected logs: %v", logs)
+	}
+}
+
+func TestRoundTripperFunc_Compose(t *testing.T) {
+	var seen *http.Request
+	attempts := 0
+	failing := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		io.ReadAll(req.Body)
+		return nil, errors.New("connection refused")
+	})
+	transport := failing.Compose(okTransport(&seen))
+
+	req, _ := http.NewRequest("POST", "http://primary/", strings.NewReader("payload"))
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected fallback response, got %v", err)
+	}
+	body, _ := io.ReadAll(seen.Body)
+	if attempts != 1 || string(body) != "payload" {
+		t.Errorf("expected rewound body on fallback, got %q", body)
+	}
+
+	req, _ = http.NewRequest("POST", "http://primary/", io.NopCloser(strings.NewReader("stream")))
+	if _, err := transport.RoundTrip(req); err == nil {
+		t.Error("expected no fallback for a body that cannot be rewound")
+	}
+}
+
+func TestRoundTripperFunc_WithTimeout(t *testing.T) {
+	slow := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	})
+	req, _ := http.NewRequest("GET", "http://slow/", nil)
+	if _, err := slow.WithTimeout(10 * time.Millisecond).RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+
+	var ctxErr error
+	fast := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: ReadWriteCloser{
+			ReadFunc:  strings.NewReader("ok").Read,
+			CloseFunc: func() error { ctxErr = req.Context().Err(); return nil },
+		}}, nil
+	}).WithTimeout(time.Second)
+	resp, err := fast.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(resp.Body); string(data) != "ok" {
+		t.Errorf("expected body readable before close, got %q", data)
+	}
+	resp.Body.Close()
+	if ctxErr != nil {
+		t.Errorf("expected context alive while reading, got %v", ctxErr)
+	}
+}
+
 // ============================================================================
 // StringerFunc Tests
 // ============================================================================
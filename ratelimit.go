@@ -0,0 +1,275 @@
+package purefunccore
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Rate Limiting
+// ============================================================================
+
+// RateLimitAlgorithm selects how requests are counted.
+type RateLimitAlgorithm int
+
+const (
+	// TokenBucket allows bursts up to Limit and refills Limit tokens per Window.
+	TokenBucket RateLimitAlgorithm = iota
+	// SlidingWindow approximates a rolling window by weighting the previous
+	// fixed window's count against the current one.
+	SlidingWindow
+)
+
+// RateLimitRule describes the quota enforced for a single key.
+type RateLimitRule struct {
+	Algorithm RateLimitAlgorithm
+	Limit     int
+	Window    time.Duration
+}
+
+// RateLimitResult is the outcome of a single rate limit check.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // until the quota is fully restored
+	RetryAfter time.Duration // until the next request would be allowed
+}
+
+// RateLimitStore records request counts per key.
+// Implement it to share limits across instances (e.g. backed by Redis).
+type RateLimitStore interface {
+	Allow(ctx context.Context, key string, rule RateLimitRule, now time.Time) (RateLimitResult, error)
+}
+
+// RateLimitStoreFunc is a functional binding for RateLimitStore.
+type RateLimitStoreFunc func(ctx context.Context, key string, rule RateLimitRule, now time.Time) (RateLimitResult, error)
+
+// Allow implements RateLimitStore.
+func (f RateLimitStoreFunc) Allow(ctx context.Context, key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	return f(ctx, key, rule, now)
+}
+
+// RateLimitConfig configures HandlerFunc.WithRateLimit.
+type RateLimitConfig struct {
+	// Limit is the number of requests allowed per Window.
+	Limit  int
+	Window time.Duration
+	// Algorithm defaults to TokenBucket.
+	Algorithm RateLimitAlgorithm
+	// Key derives the bucket for a request. Defaults to KeyByIP.
+	// Requests with an empty key are not limited.
+	Key func(*http.Request) string
+	// Store defaults to a new in-memory store.
+	Store RateLimitStore
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+}
+
+// WithRateLimit rejects requests exceeding the configured quota with 429.
+// Every response carries RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers; rejections also carry Retry-After.
+// Store errors fail open so an unavailable backend does not take the API down.
+// It panics if Limit is below one or Window is not positive.
+//
+// Example:
+//
+//	handler := HandlerFunc(search).WithRateLimit(RateLimitConfig{
+//	    Limit:  100,
+//	    Window: time.Minute,
+//	    Key:    KeyByHeader("X-API-Key"),
+//	})
+func (f HandlerFunc) WithRateLimit(cfg RateLimitConfig) HandlerFunc {
+	if cfg.Limit < 1 || cfg.Window <= 0 {
+		panic(fmt.Sprintf("purefunccore: invalid rate limit %d per %s", cfg.Limit, cfg.Window))
+	}
+	key := cfg.Key
+	if key == nil {
+		key = KeyByIP
+	}
+	store := cfg.Store
+	if store == nil {
+		store = NewMemoryRateLimitStore(0)
+	}
+	now := cfg.Now
+	if now == nil {
+		now = time.Now
+	}
+	rule := RateLimitRule{Algorithm: cfg.Algorithm, Limit: cfg.Limit, Window: cfg.Window}
+	policy := strconv.Itoa(cfg.Limit) + ";w=" + strconv.Itoa(int(math.Ceil(cfg.Window.Seconds())))
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		k := key(r)
+		if k == "" {
+			f(w, r)
+			return
+		}
+		res, err := store.Allow(r.Context(), k, rule, now())
+		if err != nil {
+			f(w, r)
+			return
+		}
+
+		h := w.Header()
+		h.Set("RateLimit-Policy", policy)
+		h.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		h.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		h.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+		if !res.Allowed {
+			h.Set("Retry-After", ceilSeconds(res.RetryAfter))
+			http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			return
+		}
+		f(w, r)
+	}
+}
+
+func ceilSeconds(d time.Duration) string {
+	if d < 0 {
+		d = 0
+	}
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
+
+// KeyByIP keys requests by the client IP address, as resolved by
+// WithRealIP when it runs first.
+func KeyByIP(r *http.Request) string {
+	if ip := ClientIP(r); ip.IsValid() {
+		return ip.String()
+	}
+	return r.RemoteAddr
+}
+
+// KeyByHeader keys requests by the value of a header such as X-API-Key.
+func KeyByHeader(name string) func(*http.Request) string {
+	return func(r *http.Request) string {
+		return r.Header.Get(name)
+	}
+}
+
+// KeyByPrincipal keys requests by the subject of the authenticated Principal.
+func KeyByPrincipal(r *http.Request) string {
+	if p, ok := PrincipalFromContext(r.Context()); ok {
+		return p.Subject
+	}
+	return ""
+}
+
+// MemoryRateLimitStore is an in-process RateLimitStore.
+// Idle keys are evicted once they have not been seen for the TTL.
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	entries   map[string]*rateLimitEntry
+	lastSweep time.Time
+}
+
+type rateLimitEntry struct {
+	lastSeen time.Time
+	// token bucket
+	tokens float64
+	// sliding window
+	windowStart time.Time
+	prev, curr  int
+}
+
+// NewMemoryRateLimitStore creates an in-memory store. A ttl of zero evicts
+// keys after two windows of inactivity, when their state is back to full.
+func NewMemoryRateLimitStore(ttl time.Duration) *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{ttl: ttl, entries: make(map[string]*rateLimitEntry)}
+}
+
+// Len returns the number of tracked keys.
+func (s *MemoryRateLimitStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Allow implements RateLimitStore.
+func (s *MemoryRateLimitStore) Allow(_ context.Context, key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ttl := s.ttl
+	if ttl <= 0 {
+		ttl = 2 * rule.Window
+	}
+	if now.Sub(s.lastSweep) >= ttl {
+		for k, e := range s.entries {
+			if now.Sub(e.lastSeen) >= ttl {
+				delete(s.entries, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	e, ok := s.entries[key]
+	if !ok {
+		e = &rateLimitEntry{tokens: float64(rule.Limit), lastSeen: now, windowStart: now.Truncate(rule.Window)}
+		s.entries[key] = e
+	}
+	defer func() { e.lastSeen = now }()
+
+	if rule.Algorithm == SlidingWindow {
+		return e.slidingWindow(rule, now), nil
+	}
+	return e.tokenBucket(rule, now), nil
+}
+
+func (e *rateLimitEntry) tokenBucket(rule RateLimitRule, now time.Time) RateLimitResult {
+	capacity := float64(rule.Limit)
+	rate := capacity / rule.Window.Seconds() // tokens per second
+	e.tokens = math.Min(capacity, e.tokens+now.Sub(e.lastSeen).Seconds()*rate)
+
+	res := RateLimitResult{Limit: rule.Limit}
+	if e.tokens >= 1 {
+		e.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = time.Duration((1 - e.tokens) / rate * float64(time.Second))
+	}
+	res.Remaining = int(e.tokens)
+	res.Reset = time.Duration((capacity - e.tokens) / rate * float64(time.Second))
+	return res
+}
+
+func (e *rateLimitEntry) slidingWindow(rule RateLimitRule, now time.Time) RateLimitResult {
+	start := now.Truncate(rule.Window)
+	if !start.Equal(e.windowStart) {
+		if start.Sub(e.windowStart) == rule.Window {
+			e.prev = e.curr
+		} else {
+			e.prev = 0
+		}
+		e.curr = 0
+		e.windowStart = start
+	}
+
+	elapsed := now.Sub(start)
+	weight := 1 - float64(elapsed)/float64(rule.Window)
+	estimate := float64(e.prev)*weight + float64(e.curr)
+
+	res := RateLimitResult{Limit: rule.Limit, Reset: rule.Window - elapsed}
+	if estimate+1 <= float64(rule.Limit) {
+		e.curr++
+		estimate++
+		res.Allowed = true
+	} else if e.curr+1 > rule.Limit || e.prev == 0 {
+		res.RetryAfter = rule.Window - elapsed
+	} else {
+		// Wait until the previous window's weight has decayed enough.
+		needed := 1 - float64(rule.Limit-1-e.curr)/float64(e.prev)
+		res.RetryAfter = time.Duration(needed*float64(rule.Window)) - elapsed
+	}
+	res.Remaining = max(0, rule.Limit-int(math.Ceil(estimate)))
+	if e.prev > 0 {
+		res.Reset += rule.Window
+	}
+	return res
+}
@@ -0,0 +1,156 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Rate Limit Tests
+// ============================================================================
+
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time          { return c.now }
+func (c *fakeClock) Advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func TestHandlerFunc_WithRateLimit_TokenBucket(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0)}
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).WithRateLimit(RateLimitConfig{Limit: 2, Window: 10 * time.Second, Now: clock.Now})
+
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	for i := 0; i < 2; i++ {
+		if w := serve(); w.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, w.Code)
+		}
+	}
+
+	w := serve()
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "5" {
+		t.Errorf("expected Retry-After 5, got '%s'", got)
+	}
+	if got := w.Header().Get("RateLimit-Remaining"); got != "0" {
+		t.Errorf("expected RateLimit-Remaining 0, got '%s'", got)
+	}
+	if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+		t.Errorf("expected RateLimit-Limit 2, got '%s'", got)
+	}
+
+	clock.Advance(5 * time.Second)
+	if w := serve(); w.Code != http.StatusOK {
+		t.Errorf("expected refill to allow a request, got %d", w.Code)
+	}
+}
+
+func TestHandlerFunc_WithRateLimit_SlidingWindow(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1_700_000_000, 0).Truncate(time.Minute)}
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).WithRateLimit(RateLimitConfig{
+		Limit:     4,
+		Window:    time.Minute,
+		Algorithm: SlidingWindow,
+		Key:       KeyByHeader("X-API-Key"),
+		Now:       clock.Now,
+	})
+
+	serve := func(apiKey string) int {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-API-Key", apiKey)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	for i := 0; i < 4; i++ {
+		serve("a")
+	}
+	if code := serve("a"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 once window is full, got %d", code)
+	}
+	if code := serve("b"); code != http.StatusOK {
+		t.Errorf("expected other keys to be unaffected, got %d", code)
+	}
+
+	// Halfway through the next window the previous 4 count as 2.
+	clock.Advance(90 * time.Second)
+	if code := serve("a"); code != http.StatusOK {
+		t.Errorf("expected 200 at half weight, got %d", code)
+	}
+	if code := serve("a"); code != http.StatusOK {
+		t.Errorf("expected 200 at half weight, got %d", code)
+	}
+	if code := serve("a"); code != http.StatusTooManyRequests {
+		t.Errorf("expected 429 when weighted estimate reaches limit, got %d", code)
+	}
+}
+
+func TestHandlerFunc_WithRateLimit_StoreErrorFailsOpen(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}).WithRateLimit(RateLimitConfig{
+		Limit:  1,
+		Window: time.Second,
+		Store: RateLimitStoreFunc(func(ctx context.Context, key string, rule RateLimitRule, now time.Time) (RateLimitResult, error) {
+			return RateLimitResult{}, errors.New("store unavailable")
+		}),
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200, got %d", w.Code)
+	}
+}
+
+func TestMemoryRateLimitStore_Eviction(t *testing.T) {
+	store := NewMemoryRateLimitStore(time.Minute)
+	rule := RateLimitRule{Limit: 10, Window: time.Second}
+	now := time.Unix(1_700_000_000, 0)
+
+	store.Allow(context.Background(), "a", rule, now)
+	store.Allow(context.Background(), "b", rule, now)
+	if store.Len() != 2 {
+		t.Fatalf("expected 2 keys, got %d", store.Len())
+	}
+
+	store.Allow(context.Background(), "c", rule, now.Add(2*time.Minute))
+	if store.Len() != 1 {
+		t.Errorf("expected idle keys to be evicted, got %d keys", store.Len())
+	}
+}
+
+func TestHandlerFunc_WithRateLimit_InvalidConfig(t *testing.T) {
+	for _, cfg := range []RateLimitConfig{
+		{Limit: 10},
+		{Window: time.Minute},
+		{Limit: 10, Window: -time.Second},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic for %d per %s", cfg.Limit, cfg.Window)
+				}
+			}()
+			HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}).WithRateLimit(cfg)
+		}()
+	}
+}
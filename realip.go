@@ -0,0 +1,221 @@
+package purefunccore
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// ============================================================================
+// Client IP Resolution
+// ============================================================================
+
+type clientInfoKey struct{}
+
+type clientInfo struct {
+	ip     netip.Addr
+	scheme string
+}
+
+// ClientIPFromContext returns the client IP resolved by WithRealIP.
+func ClientIPFromContext(ctx context.Context) (netip.Addr, bool) {
+	info, ok := ctx.Value(clientInfoKey{}).(clientInfo)
+	return info.ip, ok
+}
+
+// SchemeFromContext returns the original request scheme ("http" or
+// "https") resolved by WithRealIP, or "" if it did not run.
+func SchemeFromContext(ctx context.Context) string {
+	info, _ := ctx.Value(clientInfoKey{}).(clientInfo)
+	return info.scheme
+}
+
+// ClientIP returns the client IP resolved by WithRealIP, falling back to
+// the address of the connection's peer.
+func ClientIP(r *http.Request) netip.Addr {
+	if ip, ok := ClientIPFromContext(r.Context()); ok {
+		return ip
+	}
+	return parseHostIP(r.RemoteAddr)
+}
+
+// WithRealIP resolves the client IP and scheme behind reverse proxies and
+// stores them in the request context (see ClientIP and SchemeFromContext).
+// Forwarding headers are only believed when the connection comes from one
+// of the trusted CIDRs (a bare IP trusts that address alone). Forwarded
+// (RFC 7239) takes precedence over X-Forwarded-For, which takes precedence
+// over X-Real-IP. Hops are read right to left, skipping trusted proxies,
+// so a client cannot spoof its address by sending the headers itself.
+//
+// WithRealIP panics if a CIDR is invalid.
+//
+// Example:
+//
+//	handler := HandlerFunc(api).
+//	    WithRateLimit(RateLimitConfig{Limit: 100, Window: time.Minute}).
+//	    WithRealIP("10.0.0.0/8", "fd00::/8")
+func (f HandlerFunc) WithRealIP(trustedCIDRs ...string) HandlerFunc {
+	trusted := mustParsePrefixes(trustedCIDRs)
+	isTrusted := func(ip netip.Addr) bool { return ip.IsValid() && matchPrefixes(ip, trusted) }
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		info := clientInfo{ip: parseHostIP(r.RemoteAddr), scheme: "http"}
+		if r.TLS != nil {
+			info.scheme = "https"
+		}
+		if isTrusted(info.ip) {
+			hops := forwardedHops(r.Header)
+			for i := len(hops) - 1; i >= 0; i-- {
+				if !hops[i].ip.IsValid() {
+					break
+				}
+				info.ip = hops[i].ip
+				if hops[i].proto != "" {
+					info.scheme = hops[i].proto
+				}
+				if !isTrusted(hops[i].ip) {
+					break
+				}
+			}
+		}
+		f(w, r.WithContext(context.WithValue(r.Context(), clientInfoKey{}, info)))
+	}
+}
+
+type forwardedHop struct {
+	ip    netip.Addr
+	proto string
+}
+
+// forwardedHops lists the client and proxy addresses recorded by proxies,
+// nearest to the client first.
+func forwardedHops(h http.Header) []forwardedHop {
+	var hops []forwardedHop
+	if values := h.Values("Forwarded"); len(values) > 0 {
+		for _, element := range strings.Split(strings.Join(values, ","), ",") {
+			var hop forwardedHop
+			for _, pair := range strings.Split(element, ";") {
+				key, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				value = strings.Trim(value, `"`)
+				switch strings.ToLower(key) {
+				case "for":
+					hop.ip = parseHostIP(value)
+				case "proto":
+					hop.proto = parseProto(value)
+				}
+			}
+			hops = append(hops, hop)
+		}
+		return hops
+	}
+	if values := h.Values("X-Forwarded-For"); len(values) > 0 {
+		var protos []string
+		for _, p := range strings.Split(h.Get("X-Forwarded-Proto"), ",") {
+			protos = append(protos, parseProto(p))
+		}
+		addrs := strings.Split(strings.Join(values, ","), ",")
+		for i, addr := range addrs {
+			hop := forwardedHop{ip: parseHostIP(strings.TrimSpace(addr))}
+			switch {
+			case len(protos) == len(addrs):
+				hop.proto = protos[i]
+			case i == len(addrs)-1:
+				// A single proto was set by the nearest proxy, which also
+				// appended the last address.
+				hop.proto = protos[0]
+			}
+			hops = append(hops, hop)
+		}
+		return hops
+	}
+	if v := h.Get("X-Real-IP"); v != "" {
+		return []forwardedHop{{ip: parseHostIP(strings.TrimSpace(v))}}
+	}
+	return nil
+}
+
+// parseProto accepts only the schemes a proxy can legitimately report.
+func parseProto(s string) string {
+	switch p := strings.ToLower(strings.TrimSpace(s)); p {
+	case "http", "https":
+		return p
+	}
+	return ""
+}
+
+// parseHostIP parses an address with an optional port and IPv6 brackets.
+// It returns the zero Addr for obfuscated or unknown identifiers.
+func parseHostIP(s string) netip.Addr {
+	if host, _, err := net.SplitHostPort(s); err == nil {
+		s = host
+	}
+	ip, err := netip.ParseAddr(strings.Trim(s, "[]"))
+	if err != nil {
+		return netip.Addr{}
+	}
+	return ip.Unmap()
+}
+
+func mustParsePrefixes(cidrs []string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		if !strings.Contains(cidr, "/") {
+			ip, err := netip.ParseAddr(cidr)
+			if err != nil {
+				panic(fmt.Sprintf("purefunccore: invalid IP %q: %v", cidr, err))
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			panic(fmt.Sprintf("purefunccore: invalid CIDR %q: %v", cidr, err))
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+func matchPrefixes(ip netip.Addr, prefixes []netip.Prefix) bool {
+	for _, p := range prefixes {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// IPFilterOptions configures IPFilter. Entries are CIDRs or bare IPs.
+type IPFilterOptions struct {
+	// Allow, if not empty, admits only matching clients.
+	Allow []string
+	// Deny rejects matching clients, even if they are also allowed.
+	Deny []string
+}
+
+// IPFilter returns middleware replying 403 Forbidden to clients outside the
+// allow list or inside the deny list. It uses ClientIP, so place it inside
+// WithRealIP when running behind proxies. IPFilter panics if an entry is
+// invalid.
+//
+// Example:
+//
+//	admin := IPFilter(IPFilterOptions{Allow: []string{"10.0.0.0/8"}})
+//	router.Group("/admin", admin)
+func IPFilter(opts IPFilterOptions) Middleware {
+	allow := mustParsePrefixes(opts.Allow)
+	deny := mustParsePrefixes(opts.Deny)
+	return func(next HandlerFunc) HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			ip := ClientIP(r)
+			if !ip.IsValid() || matchPrefixes(ip, deny) || (len(allow) > 0 && !matchPrefixes(ip, allow)) {
+				http.Error(w, "Forbidden", http.StatusForbidden)
+				return
+			}
+			next(w, r)
+		}
+	}
+}
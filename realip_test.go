@@ -0,0 +1,107 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================================================
+// Client IP Tests
+// ============================================================================
+
+func TestHandlerFunc_WithRealIP(t *testing.T) {
+	var ip, scheme string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip = ClientIP(r).String()
+		scheme = SchemeFromContext(r.Context())
+	}).WithRealIP("10.0.0.0/8", "192.168.1.1")
+
+	tests := []struct {
+		name       string
+		remote     string
+		headers    map[string]string
+		ip, scheme string
+	}{
+		{"direct client", "203.0.113.9:1234", nil, "203.0.113.9", "http"},
+		{"untrusted peer headers ignored", "203.0.113.9:1234",
+			map[string]string{"X-Forwarded-For": "1.2.3.4"}, "203.0.113.9", "http"},
+		{"x-forwarded-for", "10.0.0.1:80",
+			map[string]string{"X-Forwarded-For": "198.51.100.7", "X-Forwarded-Proto": "https"}, "198.51.100.7", "https"},
+		{"spoofed leftmost entry", "10.0.0.1:80",
+			map[string]string{"X-Forwarded-For": "6.6.6.6, 198.51.100.7, 10.0.0.2"}, "198.51.100.7", "http"},
+		{"single proto from nearest proxy", "10.0.0.1:80",
+			map[string]string{"X-Forwarded-For": "6.6.6.6, 1.2.3.4", "X-Forwarded-Proto": "https"}, "1.2.3.4", "https"},
+		{"unknown proto ignored", "10.0.0.1:80",
+			map[string]string{"X-Forwarded-For": "1.2.3.4", "X-Forwarded-Proto": "javascript"}, "1.2.3.4", "http"},
+		{"forwarded header", "192.168.1.1:80",
+			map[string]string{"Forwarded": `for="[2001:db8::17]:4711";proto=https, for=10.0.0.3`}, "2001:db8::17", "https"},
+		{"forwarded wins over xff", "10.0.0.1:80",
+			map[string]string{"Forwarded": "for=198.51.100.1", "X-Forwarded-For": "198.51.100.2"}, "198.51.100.1", "http"},
+		{"x-real-ip", "10.0.0.1:80",
+			map[string]string{"X-Real-IP": "198.51.100.3"}, "198.51.100.3", "http"},
+		{"obfuscated hop stops", "10.0.0.1:80",
+			map[string]string{"Forwarded": "for=_hidden, for=10.0.0.5"}, "10.0.0.5", "http"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remote
+		for k, v := range tt.headers {
+			req.Header.Set(k, v)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+
+		if ip != tt.ip || scheme != tt.scheme {
+			t.Errorf("%s: expected %s %s, got %s %s", tt.name, tt.ip, tt.scheme, ip, scheme)
+		}
+	}
+}
+
+func TestIPFilter(t *testing.T) {
+	handler := IPFilter(IPFilterOptions{
+		Allow: []string{"10.0.0.0/8", "2001:db8::/32"},
+		Deny:  []string{"10.0.0.66"},
+	}).Then(func(w http.ResponseWriter, r *http.Request) {}).WithRealIP("127.0.0.1")
+
+	tests := []struct {
+		remote, forwarded string
+		expected          int
+	}{
+		{"10.1.2.3:80", "", http.StatusOK},
+		{"[2001:db8::1]:80", "", http.StatusOK},
+		{"10.0.0.66:80", "", http.StatusForbidden},
+		{"203.0.113.9:80", "", http.StatusForbidden},
+		{"127.0.0.1:80", "10.9.9.9", http.StatusOK},
+		{"127.0.0.1:80", "203.0.113.9", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = tt.remote
+		if tt.forwarded != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwarded)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("%s via %q: expected %d, got %d", tt.remote, tt.forwarded, tt.expected, w.Code)
+		}
+	}
+}
+
+func TestKeyByIP_UsesRealIP(t *testing.T) {
+	var key string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key = KeyByIP(r)
+	}).WithRealIP("10.0.0.0/8")
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "10.0.0.1:80"
+	req.Header.Set("X-Forwarded-For", "198.51.100.7")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if key != "198.51.100.7" {
+		t.Errorf("expected resolved client IP, got '%s'", key)
+	}
+}
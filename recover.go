@@ -0,0 +1,115 @@
+package purefunccore
+
+import (
+	"fmt"
+	"net/http"
+	"runtime/debug"
+)
+
+// ============================================================================
+// Panic Recovery
+// ============================================================================
+
+// PanicError is a recovered panic together with the stack that raised it.
+type PanicError struct {
+	Value any
+	Stack []byte
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+func newPanicError(v any) *PanicError {
+	return &PanicError{Value: v, Stack: debug.Stack()}
+}
+
+// RecoverOptions configures HandlerFunc.RecoverWith.
+type RecoverOptions struct {
+	// OnPanic reports the recovered panic, e.g. to a logger or error tracker.
+	OnPanic func(r *http.Request, err *PanicError)
+	// ProblemJSON replies with application/problem+json instead of text.
+	ProblemJSON bool
+}
+
+// RecoverWith recovers panics, reports them to opts.OnPanic and replies with
+// a generic 500 if the handler has not written a response yet. The panic
+// value is never sent to the client. http.ErrAbortHandler is re-panicked so
+// net/http can abort the response as intended.
+//
+// Example:
+//
+//	handler := HandlerFunc(checkout).RecoverWith(RecoverOptions{
+//	    OnPanic: func(r *http.Request, err *PanicError) {
+//	        log.Printf("%s %s: %v\n%s", r.Method, r.URL.Path, err.Value, err.Stack)
+//	    },
+//	    ProblemJSON: true,
+//	})
+func (f HandlerFunc) RecoverWith(opts RecoverOptions) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		sw := newStatusWriter(w)
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+			if opts.OnPanic != nil {
+				opts.OnPanic(r, newPanicError(v))
+			}
+			if sw.wroteHeader {
+				return
+			}
+			if opts.ProblemJSON {
+				WriteProblem(sw, NewProblem(http.StatusInternalServerError, ""))
+				return
+			}
+			http.Error(sw, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		}()
+		f(sw, r)
+	}
+}
+
+// Recover converts panics during Read into a *PanicError.
+func (f ReadFunc) Recover() ReadFunc {
+	return func(p []byte) (n int, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				n, err = 0, newPanicError(v)
+			}
+		}()
+		return f(p)
+	}
+}
+
+// Recover converts panics during Write into a *PanicError.
+func (f WriteFunc) Recover() WriteFunc {
+	return func(p []byte) (n int, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				n, err = 0, newPanicError(v)
+			}
+		}()
+		return f(p)
+	}
+}
+
+// Recover converts panics during RoundTrip into a *PanicError.
+func (f RoundTripperFunc) Recover() RoundTripperFunc {
+	return func(req *http.Request) (resp *http.Response, err error) {
+		defer func() {
+			if v := recover(); v != nil {
+				resp, err = nil, newPanicError(v)
+			}
+		}()
+		return f(req)
+	}
+}
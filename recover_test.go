@@ -0,0 +1,116 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Recover Tests
+// ============================================================================
+
+func TestHandlerFunc_Recover_DoesNotLeakPanicValue(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("db password is hunter2")
+	}).Recover()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "hunter2") {
+		t.Errorf("expected panic value to stay private, got '%s'", w.Body.String())
+	}
+}
+
+func TestHandlerFunc_RecoverWith(t *testing.T) {
+	var reported *PanicError
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(errors.New("boom"))
+	}).RecoverWith(RecoverOptions{
+		OnPanic:     func(r *http.Request, err *PanicError) { reported = err },
+		ProblemJSON: true,
+	})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500, got %d", w.Code)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("expected problem+json, got '%s'", ct)
+	}
+	if reported == nil {
+		t.Fatal("expected panic to be reported")
+	}
+	if reported.Unwrap() == nil || reported.Unwrap().Error() != "boom" {
+		t.Errorf("expected wrapped error 'boom', got %v", reported.Unwrap())
+	}
+	if !strings.Contains(string(reported.Stack), "recover_test.go") {
+		t.Error("expected stack trace to include the panicking frame")
+	}
+}
+
+func TestHandlerFunc_RecoverWith_AfterWrite(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("partial"))
+		panic("late failure")
+	}).Recover()
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusAccepted {
+		t.Errorf("expected original status 202, got %d", w.Code)
+	}
+	if w.Body.String() != "partial" {
+		t.Errorf("expected body untouched, got '%s'", w.Body.String())
+	}
+}
+
+func TestHandlerFunc_RecoverWith_ErrAbortHandler(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}).Recover()
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("expected ErrAbortHandler to propagate, got %v", v)
+		}
+	}()
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestFuncs_Recover(t *testing.T) {
+	_, err := ReadFunc(func(p []byte) (int, error) { panic("read") }).Recover().Read(make([]byte, 1))
+	var pe *PanicError
+	if !errors.As(err, &pe) || pe.Value != "read" {
+		t.Errorf("expected read panic error, got %v", err)
+	}
+
+	_, err = WriteFunc(func(p []byte) (int, error) { panic("write") }).Recover().Write([]byte("x"))
+	if !errors.As(err, &pe) || pe.Value != "write" {
+		t.Errorf("expected write panic error, got %v", err)
+	}
+
+	resp, err := RoundTripperFunc(func(*http.Request) (*http.Response, error) { panic("rt") }).
+		Recover().
+		RoundTrip(httptest.NewRequest("GET", "/", nil))
+	if resp != nil || !errors.As(err, &pe) || pe.Value != "rt" {
+		t.Errorf("expected round trip panic error, got %v", err)
+	}
+
+	n, err := ReadFunc(func(p []byte) (int, error) { return copy(p, "ok"), io.EOF }).Recover().Read(make([]byte, 4))
+	if n != 2 || err != io.EOF {
+		t.Errorf("expected pass-through read, got %d %v", n, err)
+	}
+}
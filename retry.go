@@ -0,0 +1,153 @@
+package purefunccore
+
+import (
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ============================================================================
+// Client Retries
+// ============================================================================
+
+// RetryPolicy configures RoundTripperFunc.Retry.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts. Defaults to 3.
+	MaxAttempts int
+	// BaseDelay is the backoff before the first retry; it doubles on each
+	// further retry. Defaults to 100ms.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff. A Retry-After longer than MaxDelay is not
+	// waited for; the response is returned instead. Defaults to 10 seconds.
+	MaxDelay time.Duration
+	// RetryStatuses lists the response codes worth retrying.
+	// Defaults to 429, 502, 503 and 504.
+	RetryStatuses []int
+	// Now returns the current time, used for HTTP-date Retry-After values.
+	// Defaults to time.Now.
+	Now func() time.Time
+}
+
+// Retry resends requests that fail with a connection error or a retryable
+// status, using exponential backoff with jitter and honoring Retry-After.
+// Only idempotent methods (GET, HEAD, OPTIONS, TRACE, PUT, DELETE) and
+// requests carrying an Idempotency-Key are retried, and bodies are rewound
+// with GetBody. Discarded responses are drained and closed so connections
+// are reused. Waiting stops as soon as the request context is done.
+//
+// Example:
+//
+//	transport := RoundTripperFunc(nil).Empty().
+//	    Retry(RetryPolicy{MaxAttempts: 4}).
+//	    WithTimeout(2 * time.Second)
+func (f RoundTripperFunc) Retry(policy RetryPolicy) RoundTripperFunc {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = 100 * time.Millisecond
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = 10 * time.Second
+	}
+	if policy.RetryStatuses == nil {
+		policy.RetryStatuses = []int{
+			http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout,
+		}
+	}
+	if policy.Now == nil {
+		policy.Now = time.Now
+	}
+
+	return func(req *http.Request) (*http.Response, error) {
+		if !isRetryable(req) {
+			return f(req)
+		}
+		ctx := req.Context()
+		attemptReq := req
+		for attempt := 1; ; attempt++ {
+			resp, err := f(attemptReq)
+			if attempt == policy.MaxAttempts || ctx.Err() != nil {
+				return resp, err
+			}
+			if err == nil && !slices.Contains(policy.RetryStatuses, resp.StatusCode) {
+				return resp, nil
+			}
+
+			delay := backoff(policy.BaseDelay, policy.MaxDelay, attempt)
+			if err == nil {
+				if after, ok := parseRetryAfter(resp.Header.Get("Retry-After"), policy.Now()); ok {
+					if after > policy.MaxDelay {
+						return resp, nil
+					}
+					delay = max(delay, after)
+				}
+			}
+			next, rerr := rewindRequest(req)
+			if rerr != nil {
+				return resp, err
+			}
+			if resp != nil {
+				drainBody(resp.Body)
+			}
+
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+			attemptReq = next
+		}
+	}
+}
+
+func isRetryable(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace,
+		http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// backoff returns the delay before retry number attempt, doubling from base
+// and capped at maxDelay, with "equal jitter": half fixed, half random.
+func backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	d := base
+	for i := 1; i < attempt && d < maxDelay; i++ {
+		d *= 2
+	}
+	d = min(d, maxDelay)
+	half := d / 2
+	return half + time.Duration(rand.Int64N(int64(half)+1))
+}
+
+// parseRetryAfter reads delay-seconds or an HTTP date.
+func parseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		return max(t.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// drainBody reads a little of a discarded body so the connection can be
+// reused, then closes it.
+func drainBody(body io.ReadCloser) {
+	if body == nil {
+		return
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, 64<<10))
+	_ = body.Close()
+}
@@ -0,0 +1,121 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Retry Tests
+// ============================================================================
+
+type trackedBody struct {
+	io.Reader
+	closed *int
+}
+
+func (b trackedBody) Close() error { *b.closed++; return nil }
+
+func TestRoundTripperFunc_Retry(t *testing.T) {
+	var bodies []string
+	closed := 0
+	responses := []int{http.StatusServiceUnavailable, 0, http.StatusOK}
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		data, _ := io.ReadAll(req.Body)
+		bodies = append(bodies, string(data))
+		status := responses[len(bodies)-1]
+		if status == 0 {
+			return nil, errors.New("connection reset")
+		}
+		return &http.Response{StatusCode: status, Body: trackedBody{strings.NewReader("x"), &closed}}, nil
+	}).Retry(RetryPolicy{BaseDelay: time.Millisecond})
+
+	req, _ := http.NewRequest("PUT", "http://api/items/1", strings.NewReader("data"))
+	resp, err := transport.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected eventual 200, got %v", err)
+	}
+	if strings.Join(bodies, ",") != "data,data,data" {
+		t.Errorf("expected body rewound on every attempt, got %q", bodies)
+	}
+	if closed != 1 {
+		t.Errorf("expected the discarded response to be closed, got %d", closed)
+	}
+}
+
+func TestRoundTripperFunc_Retry_OnlyIdempotent(t *testing.T) {
+	attempts := 0
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return &http.Response{StatusCode: http.StatusBadGateway, Body: http.NoBody}, nil
+	}).Retry(RetryPolicy{BaseDelay: time.Millisecond})
+
+	req, _ := http.NewRequest("POST", "http://api/payments", strings.NewReader("{}"))
+	resp, _ := transport.RoundTrip(req)
+	if attempts != 1 || resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("expected POST not to be retried, got %d attempts", attempts)
+	}
+
+	attempts = 0
+	req, _ = http.NewRequest("POST", "http://api/payments", strings.NewReader("{}"))
+	req.Header.Set("Idempotency-Key", "abc")
+	transport.RoundTrip(req)
+	if attempts != 3 {
+		t.Errorf("expected POST with Idempotency-Key to be retried, got %d attempts", attempts)
+	}
+}
+
+func TestRoundTripperFunc_Retry_RetryAfter(t *testing.T) {
+	var times []time.Time
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		times = append(times, time.Now())
+		h := http.Header{"Retry-After": {"1"}}
+		if len(times) == 1 {
+			h.Set("Retry-After", "0")
+		}
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: h, Body: http.NoBody}, nil
+	}).Retry(RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 500 * time.Millisecond})
+
+	req, _ := http.NewRequest("GET", "http://api/", nil)
+	resp, _ := transport.RoundTrip(req)
+	if len(times) != 2 || resp.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("expected Retry-After beyond MaxDelay to stop retrying, got %d attempts", len(times))
+	}
+
+	if d, ok := parseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT", time.Date(2015, 10, 21, 7, 27, 0, 0, time.UTC)); !ok || d != time.Minute {
+		t.Errorf("expected HTTP-date Retry-After of 1m, got %v", d)
+	}
+}
+
+func TestRoundTripperFunc_Retry_ContextCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		cancel()
+		return nil, errors.New("connection refused")
+	}).Retry(RetryPolicy{BaseDelay: time.Hour})
+
+	req, _ := http.NewRequestWithContext(ctx, "GET", "http://api/", nil)
+	start := time.Now()
+	_, err := transport.RoundTrip(req)
+	if attempts != 1 || err == nil || time.Since(start) > time.Second {
+		t.Errorf("expected to stop on cancellation, got %d attempts, %v", attempts, err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 1; attempt <= 40; attempt++ {
+		d := backoff(100*time.Millisecond, time.Second, attempt)
+		expected := min(100*time.Millisecond<<min(attempt-1, 10), time.Second)
+		if d < expected/2 || d > expected {
+			t.Errorf("attempt %d: expected delay in [%v, %v], got %v", attempt, expected/2, expected, d)
+		}
+	}
+}
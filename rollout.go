@@ -0,0 +1,267 @@
+package purefunccore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"net/http"
+)
+
+// ============================================================================
+// Rollout Combinators
+// ============================================================================
+
+// Variant is a weighted alternative for HandlerFunc.Split.
+type Variant struct {
+	Name    string
+	Handler HandlerFunc
+	Weight  int
+}
+
+type variantKey struct{}
+
+// VariantFromContext returns the name of the variant chosen by Split.
+func VariantFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(variantKey{}).(string)
+	return name
+}
+
+// Split routes requests across weighted variants. The same key always
+// reaches the same variant while the weights are unchanged, so users see a
+// consistent experience. Requests with an empty key, or when no variant has
+// a positive weight, are served by f. The chosen variant's name is stored
+// in the request context.
+//
+// Variants are a slice rather than a map keyed by handler because Go
+// functions cannot be compared and so cannot be map keys.
+//
+// Example:
+//
+//	checkout := HandlerFunc(checkoutV1).Split(KeyByPrincipal,
+//	    Variant{Name: "v1", Handler: checkoutV1, Weight: 90},
+//	    Variant{Name: "v2", Handler: checkoutV2, Weight: 10},
+//	)
+func (f HandlerFunc) Split(key func(*http.Request) string, variants ...Variant) HandlerFunc {
+	total := 0
+	for _, v := range variants {
+		if v.Weight > 0 {
+			total += v.Weight
+		}
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		k := key(r)
+		if k == "" || total == 0 {
+			f(w, r)
+			return
+		}
+		h := fnv.New64a()
+		io.WriteString(h, k)
+		bucket := int(h.Sum64() % uint64(total))
+		for _, v := range variants {
+			if v.Weight <= 0 {
+				continue
+			}
+			if bucket < v.Weight {
+				v.Handler(w, r.WithContext(context.WithValue(r.Context(), variantKey{}, v.Name)))
+				return
+			}
+			bucket -= v.Weight
+		}
+	}
+}
+
+// When serves requests matching predicate with alt instead of f. Use it to
+// switch on headers, cookies or feature flags.
+//
+// Example:
+//
+//	search := HandlerFunc(searchV1).
+//	    When(CookieIs("beta", "1"), searchV2).
+//	    When(func(*http.Request) bool { return flags.Enabled("search-v2") }, searchV2)
+func (f HandlerFunc) When(predicate func(*http.Request) bool, alt HandlerFunc) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if predicate(r) {
+			alt(w, r)
+			return
+		}
+		f(w, r)
+	}
+}
+
+// ShadowResponse is a response observed by Shadow. Body holds at most the
+// first 64 KiB.
+type ShadowResponse struct {
+	Status int
+	Header http.Header
+	Body   []byte
+	// Panic is the value the candidate panicked with, if any.
+	Panic any
+}
+
+// ShadowDiff reports a request whose candidate response differed from the
+// primary one in status or body.
+type ShadowDiff struct {
+	Request   *http.Request
+	Primary   ShadowResponse
+	Candidate ShadowResponse
+}
+
+// maxShadowsInFlight bounds the candidate requests running at once; further
+// requests are not mirrored until some complete.
+const maxShadowsInFlight = 64
+
+// Shadow serves requests with f and mirrors each one to candidate in the
+// background, with a copy of the body and a context that is not cancelled
+// when the client goes away. The candidate's response is discarded; when
+// its status or body differs from the primary response, onDiff is called
+// from the background goroutine. A panicking candidate is reported rather
+// than crashing the server.
+//
+// The request body is read into memory so it can be replayed; combine with
+// WithMaxBodySize to bound it.
+//
+// Example:
+//
+//	orders := HandlerFunc(ordersV1).Shadow(ordersV2, func(d ShadowDiff) {
+//	    log.Printf("orders v2 mismatch on %s: %d vs %d", d.Request.URL, d.Primary.Status, d.Candidate.Status)
+//	})
+func (f HandlerFunc) Shadow(candidate HandlerFunc, onDiff func(ShadowDiff)) HandlerFunc {
+	slots := make(chan struct{}, maxShadowsInFlight)
+	return func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case slots <- struct{}{}:
+		default:
+			f(w, r)
+			return
+		}
+
+		mirrored := false
+		defer func() {
+			if !mirrored {
+				<-slots
+			}
+		}()
+
+		var body []byte
+		r2 := r
+		if r.Body != nil && r.Body != http.NoBody {
+			var err error
+			if body, err = io.ReadAll(r.Body); err != nil {
+				http.Error(w, "Bad Request", http.StatusBadRequest)
+				return
+			}
+			r2 = new(http.Request)
+			*r2 = *r
+			r2.Body = io.NopCloser(bytes.NewReader(body))
+		}
+		mirror := r.Clone(context.WithoutCancel(r.Context()))
+		if body != nil {
+			mirror.Body = io.NopCloser(bytes.NewReader(body))
+		}
+
+		primary := newShadowCapture(w)
+		f(primary, r2)
+		if primary.status == 0 {
+			primary.status = http.StatusOK
+		}
+		primaryResp := primary.response()
+		primarySum := primary.hash.Sum(nil)
+
+		mirrored = true
+		go func() {
+			defer func() { <-slots }()
+			shadow := newShadowCapture(nil)
+			func() {
+				defer func() {
+					if v := recover(); v != nil {
+						shadow.panicValue = v
+					}
+				}()
+				candidate(shadow, mirror)
+			}()
+			if shadow.status == 0 {
+				shadow.status = http.StatusOK
+			}
+			if onDiff == nil || (shadow.panicValue == nil && shadow.status == primaryResp.Status &&
+				bytes.Equal(shadow.hash.Sum(nil), primarySum)) {
+				return
+			}
+			onDiff(ShadowDiff{Request: mirror, Primary: primaryResp, Candidate: shadow.response()})
+		}()
+	}
+}
+
+// shadowCapture records a response's status, a hash of its body and the
+// start of the body. With a nil writer the response is discarded.
+type shadowCapture struct {
+	w          http.ResponseWriter
+	header     http.Header
+	status     int
+	hash       hash.Hash
+	body       captureBuffer
+	panicValue any
+}
+
+func newShadowCapture(w http.ResponseWriter) *shadowCapture {
+	c := &shadowCapture{w: w, hash: sha256.New(), body: captureBuffer{limit: 64 << 10}}
+	if w != nil {
+		c.header = w.Header()
+	} else {
+		c.header = make(http.Header)
+	}
+	return c
+}
+
+func (c *shadowCapture) Header() http.Header {
+	return c.header
+}
+
+func (c *shadowCapture) WriteHeader(code int) {
+	if c.status == 0 && (code < 100 || code >= 200) {
+		c.status = code
+	}
+	if c.w != nil {
+		c.w.WriteHeader(code)
+	}
+}
+
+func (c *shadowCapture) Write(p []byte) (int, error) {
+	if c.status == 0 {
+		c.status = http.StatusOK
+	}
+	n := len(p)
+	var err error
+	if c.w != nil {
+		n, err = c.w.Write(p)
+	}
+	c.hash.Write(p[:n])
+	c.body.Write(p[:n])
+	return n, err
+}
+
+func (c *shadowCapture) Flush() {
+	if c.w != nil {
+		_ = http.NewResponseController(c.w).Flush()
+	}
+}
+
+func (c *shadowCapture) Unwrap() http.ResponseWriter {
+	return c.w
+}
+
+func (c *shadowCapture) response() ShadowResponse {
+	return ShadowResponse{Status: c.status, Header: c.header.Clone(), Body: c.body.buf, Panic: c.panicValue}
+}
+
+// String summarizes the difference for logging.
+func (d ShadowDiff) String() string {
+	if d.Candidate.Panic != nil {
+		return fmt.Sprintf("%s %s: candidate panicked: %v", d.Request.Method, d.Request.URL, d.Candidate.Panic)
+	}
+	return fmt.Sprintf("%s %s: primary %d (%d bytes), candidate %d (%d bytes)",
+		d.Request.Method, d.Request.URL, d.Primary.Status, len(d.Primary.Body), d.Candidate.Status, len(d.Candidate.Body))
+}
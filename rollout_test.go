@@ -0,0 +1,134 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Rollout Combinator Tests
+// ============================================================================
+
+func TestHandlerFunc_Split(t *testing.T) {
+	var variant string
+	record := func(w http.ResponseWriter, r *http.Request) { variant = VariantFromContext(r.Context()) }
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) { variant = "default" }).
+		Split(KeyByHeader("X-User"),
+			Variant{Name: "control", Handler: record, Weight: 80},
+			Variant{Name: "treatment", Handler: record, Weight: 20},
+		)
+
+	serve := func(user string) string {
+		req := httptest.NewRequest("GET", "/", nil)
+		if user != "" {
+			req.Header.Set("X-User", user)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+		return variant
+	}
+
+	counts := map[string]int{}
+	for i := 0; i < 2000; i++ {
+		user := fmt.Sprintf("user-%d", i)
+		first := serve(user)
+		if again := serve(user); again != first {
+			t.Fatalf("expected %s to stick to %s, got %s", user, first, again)
+		}
+		counts[first]++
+	}
+	if counts["treatment"] < 300 || counts["treatment"] > 500 {
+		t.Errorf("expected about 20%% treatment, got %v", counts)
+	}
+	if serve("") != "default" {
+		t.Error("expected requests without a key to use the receiver")
+	}
+}
+
+func TestHandlerFunc_When(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "v1") }).
+		When(CookieIs("beta", "1"), func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "v2") }).
+		When(HeaderIs("X-Canary", "true"), func(w http.ResponseWriter, r *http.Request) { io.WriteString(w, "canary") })
+
+	tests := []struct {
+		header, cookie, expected string
+	}{
+		{"", "", "v1"},
+		{"", "1", "v2"},
+		{"true", "1", "canary"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set("X-Canary", tt.header)
+		}
+		if tt.cookie != "" {
+			req.AddCookie(&http.Cookie{Name: "beta", Value: tt.cookie})
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Body.String() != tt.expected {
+			t.Errorf("expected '%s', got '%s'", tt.expected, w.Body.String())
+		}
+	}
+}
+
+func TestHandlerFunc_Shadow(t *testing.T) {
+	diffs := make(chan ShadowDiff, 3)
+	primary := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		io.WriteString(w, "total="+string(body))
+	})
+	candidate := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		switch string(body) {
+		case "panic":
+			panic("not implemented")
+		case "2":
+			io.WriteString(w, "total=3")
+		default:
+			io.WriteString(w, "total="+string(body))
+		}
+	})
+	handler := primary.Shadow(candidate, func(d ShadowDiff) { diffs <- d })
+
+	for _, body := range []string{"1", "2", "panic"} {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		reqBody := req.Body
+		handler.ServeHTTP(w, req)
+		if w.Body.String() != "total="+body {
+			t.Errorf("expected primary response, got '%s'", w.Body.String())
+		}
+		if req.Body != reqBody {
+			t.Error("expected the caller's request to be left unchanged")
+		}
+	}
+
+	got := map[string]ShadowDiff{}
+	for len(got) < 2 {
+		select {
+		case d := <-diffs:
+			got[string(d.Primary.Body)] = d
+		case <-time.After(time.Second):
+			t.Fatalf("expected 2 diffs, got %d", len(got))
+		}
+	}
+	if d := got["total=2"]; string(d.Candidate.Body) != "total=3" {
+		t.Errorf("expected body mismatch reported, got %q", d.Candidate.Body)
+	}
+	if d := got["total=panic"]; d.Candidate.Panic != "not implemented" {
+		t.Errorf("expected candidate panic reported, got %v", d.Candidate.Panic)
+	}
+	select {
+	case d := <-diffs:
+		t.Errorf("expected matching responses not to be reported, got %s", d)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
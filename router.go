@@ -0,0 +1,284 @@
+package purefunccore
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// Router
+// ============================================================================
+
+// Router dispatches requests to HandlerFuncs using http.ServeMux patterns
+// ("GET /users/{id}"). It adds route groups with shared middleware,
+// automatic OPTIONS responses, named routes and route introspection.
+// ServeMux already answers HEAD from GET routes and replies 405 with an
+// Allow header when only the method does not match.
+//
+// Example:
+//
+//	router := NewRouter()
+//	router.Use(HandlerFunc.Recover)
+//	router.Get("/health", handleHealth)
+//
+//	api := router.Group("/api", func(h HandlerFunc) HandlerFunc {
+//	    return h.WithAuth(isAuthenticated).WithTimeout(5 * time.Second)
+//	})
+//	api.Get("/users/{id}", getUser).Name("user")
+//
+//	u, _ := router.URL("user", "id", "42") // "/api/users/42"
+type Router struct {
+	state      *routerState
+	parent     *Router
+	prefix     string
+	middleware []Middleware
+}
+
+type routerState struct {
+	mux    *http.ServeMux
+	mu     sync.RWMutex
+	routes []*Route
+	names  map[string]*Route
+}
+
+// Route is a registered route.
+type Route struct {
+	method  string
+	pattern string
+	name    string
+	state   *routerState
+}
+
+// RouteInfo describes a registered route for listing.
+type RouteInfo struct {
+	Method  string // empty when the route matches every method
+	Pattern string // full pattern without the method
+	Name    string
+}
+
+// NewRouter creates an empty router.
+func NewRouter() *Router {
+	return &Router{state: &routerState{mux: http.NewServeMux(), names: make(map[string]*Route)}}
+}
+
+// ServeHTTP implements http.Handler.
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := rt.state.mux.Handler(r); pattern == "" {
+		// Answer OPTIONS and 405 here so both carry the same Allow list.
+		if allow := rt.allowed(r); len(allow) > 0 {
+			w.Header().Set("Allow", strings.Join(allow, ", "))
+			if r.Method == http.MethodOptions {
+				w.WriteHeader(http.StatusNoContent)
+			} else {
+				http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			}
+			return
+		}
+	}
+	rt.state.mux.ServeHTTP(w, r)
+}
+
+// allowed returns the sorted methods that have a route matching r's path,
+// including OPTIONS.
+func (rt *Router) allowed(r *http.Request) []string {
+	rt.state.mu.RLock()
+	var methods []string
+	for _, route := range rt.state.routes {
+		if route.method != "" && !slices.Contains(methods, route.method) {
+			methods = append(methods, route.method)
+		}
+	}
+	rt.state.mu.RUnlock()
+
+	var allow []string
+	for _, m := range methods {
+		probe := r.Clone(r.Context())
+		probe.Method = m
+		if _, pattern := rt.state.mux.Handler(probe); pattern != "" {
+			allow = append(allow, m)
+			if m == http.MethodGet && !slices.Contains(methods, http.MethodHead) {
+				allow = append(allow, http.MethodHead)
+			}
+		}
+	}
+	if len(allow) > 0 {
+		allow = append(allow, http.MethodOptions)
+		slices.Sort(allow)
+	}
+	return allow
+}
+
+// Use appends middleware for routes registered afterwards on this router
+// and its groups, including groups created earlier. The first middleware
+// is the outermost.
+func (rt *Router) Use(middleware ...Middleware) {
+	rt.middleware = append(rt.middleware, middleware...)
+}
+
+// Group returns a router whose routes share a path prefix and run this
+// router's middleware followed by the given middleware.
+func (rt *Router) Group(prefix string, middleware ...Middleware) *Router {
+	return &Router{
+		state:      rt.state,
+		parent:     rt,
+		prefix:     joinRoutePath(rt.prefix, prefix),
+		middleware: slices.Clip(middleware),
+	}
+}
+
+// chain resolves the middleware of this router and its parents, outermost
+// first.
+func (rt *Router) chain() []Middleware {
+	if rt.parent == nil {
+		return rt.middleware
+	}
+	return append(slices.Clip(rt.parent.chain()), rt.middleware...)
+}
+
+// Handle registers h for a ServeMux pattern such as "POST /users" or
+// "/static/". The group prefix is prepended to the path.
+// Like ServeMux, it panics on invalid or conflicting patterns.
+func (rt *Router) Handle(pattern string, h HandlerFunc) *Route {
+	method, rest := "", pattern
+	if m, p, ok := strings.Cut(pattern, " "); ok && !strings.Contains(m, "/") {
+		method, rest = m, strings.TrimLeft(p, " \t")
+	}
+	slash := strings.Index(rest, "/")
+	if slash < 0 {
+		panic(fmt.Sprintf("purefunccore: invalid route pattern %q", pattern))
+	}
+	full := rest[:slash] + joinRoutePath(rt.prefix, rest[slash:])
+
+	h = Chain(rt.chain()...)(h)
+	muxPattern := full
+	if method != "" {
+		muxPattern = method + " " + full
+	}
+	rt.state.mux.Handle(muxPattern, recordRoute(h))
+
+	route := &Route{method: method, pattern: full, state: rt.state}
+	rt.state.mu.Lock()
+	rt.state.routes = append(rt.state.routes, route)
+	rt.state.mu.Unlock()
+	return route
+}
+
+// Get registers a GET route (which also serves HEAD).
+func (rt *Router) Get(path string, h HandlerFunc) *Route {
+	return rt.Handle(http.MethodGet+" "+path, h)
+}
+
+// Post registers a POST route.
+func (rt *Router) Post(path string, h HandlerFunc) *Route {
+	return rt.Handle(http.MethodPost+" "+path, h)
+}
+
+// Put registers a PUT route.
+func (rt *Router) Put(path string, h HandlerFunc) *Route {
+	return rt.Handle(http.MethodPut+" "+path, h)
+}
+
+// Patch registers a PATCH route.
+func (rt *Router) Patch(path string, h HandlerFunc) *Route {
+	return rt.Handle(http.MethodPatch+" "+path, h)
+}
+
+// Delete registers a DELETE route.
+func (rt *Router) Delete(path string, h HandlerFunc) *Route {
+	return rt.Handle(http.MethodDelete+" "+path, h)
+}
+
+// Name names the route for URL reversal. It panics if the name is taken.
+func (r *Route) Name(name string) *Route {
+	r.state.mu.Lock()
+	defer r.state.mu.Unlock()
+	if _, exists := r.state.names[name]; exists {
+		panic(fmt.Sprintf("purefunccore: duplicate route name %q", name))
+	}
+	r.name = name
+	r.state.names[name] = r
+	return r
+}
+
+// Routes lists the registered routes in registration order.
+func (rt *Router) Routes() []RouteInfo {
+	rt.state.mu.RLock()
+	defer rt.state.mu.RUnlock()
+	infos := make([]RouteInfo, len(rt.state.routes))
+	for i, r := range rt.state.routes {
+		infos[i] = RouteInfo{Method: r.method, Pattern: r.pattern, Name: r.name}
+	}
+	return infos
+}
+
+// URL builds the path of a named route, substituting wildcards from
+// key/value pairs. Values are path-escaped; a trailing {name...} wildcard
+// keeps its slashes.
+func (rt *Router) URL(name string, pairs ...string) (string, error) {
+	rt.state.mu.RLock()
+	route, ok := rt.state.names[name]
+	rt.state.mu.RUnlock()
+	if !ok {
+		return "", fmt.Errorf("purefunccore: unknown route %q", name)
+	}
+	if len(pairs)%2 != 0 {
+		return "", fmt.Errorf("purefunccore: odd number of URL parameters for route %q", name)
+	}
+	params := make(map[string]string, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		params[pairs[i]] = pairs[i+1]
+	}
+
+	p := route.pattern[strings.Index(route.pattern, "/"):]
+	var b strings.Builder
+	for {
+		open := strings.Index(p, "{")
+		if open < 0 {
+			b.WriteString(p)
+			break
+		}
+		end := strings.Index(p[open:], "}")
+		if end < 0 {
+			return "", fmt.Errorf("purefunccore: malformed pattern %q", route.pattern)
+		}
+		b.WriteString(p[:open])
+		wildcard := p[open+1 : open+end]
+		p = p[open+end+1:]
+		if wildcard == "$" {
+			continue
+		}
+		key, rest := strings.CutSuffix(wildcard, "...")
+		value, ok := params[key]
+		if !ok {
+			return "", fmt.Errorf("purefunccore: missing URL parameter %q for route %q", key, name)
+		}
+		if rest {
+			segments := strings.Split(value, "/")
+			for i, s := range segments {
+				segments[i] = url.PathEscape(s)
+			}
+			b.WriteString(strings.Join(segments, "/"))
+		} else {
+			b.WriteString(url.PathEscape(value))
+		}
+	}
+	return b.String(), nil
+}
+
+// joinRoutePath joins a group prefix and a route path, keeping a trailing
+// slash on the route path because it is significant to ServeMux.
+func joinRoutePath(prefix, p string) string {
+	if prefix == "" {
+		return p
+	}
+	joined := path.Join(prefix, p)
+	if strings.HasSuffix(p, "/") && !strings.HasSuffix(joined, "/") {
+		joined += "/"
+	}
+	return joined
+}
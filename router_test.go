@@ -0,0 +1,158 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================================================
+// Router Tests
+// ============================================================================
+
+func TestRouter_MethodsAndWildcards(t *testing.T) {
+	router := NewRouter()
+	router.Get("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "user %s", r.PathValue("id"))
+	})
+	router.Delete("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	tests := []struct {
+		method, path string
+		code         int
+		allow        string
+		body         string
+	}{
+		{"GET", "/users/42", http.StatusOK, "", "user 42"},
+		{"HEAD", "/users/42", http.StatusOK, "", ""},
+		{"DELETE", "/users/42", http.StatusNoContent, "", ""},
+		{"POST", "/users/42", http.StatusMethodNotAllowed, "DELETE, GET, HEAD, OPTIONS", ""},
+		{"OPTIONS", "/users/42", http.StatusNoContent, "DELETE, GET, HEAD, OPTIONS", ""},
+		{"GET", "/missing", http.StatusNotFound, "", ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		if w.Code != tt.code {
+			t.Errorf("%s %s: expected %d, got %d", tt.method, tt.path, tt.code, w.Code)
+		}
+		if tt.allow != "" && w.Header().Get("Allow") != tt.allow {
+			t.Errorf("%s %s: expected Allow '%s', got '%s'", tt.method, tt.path, tt.allow, w.Header().Get("Allow"))
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s %s: expected body '%s', got '%s'", tt.method, tt.path, tt.body, w.Body.String())
+		}
+	}
+}
+
+func TestRouter_GroupMiddleware(t *testing.T) {
+	var order []string
+	tag := func(name string) func(HandlerFunc) HandlerFunc {
+		return func(h HandlerFunc) HandlerFunc {
+			return h.Before(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+			})
+		}
+	}
+
+	router := NewRouter()
+	router.Use(tag("root"))
+	admin := router.Group("/admin", tag("admin"), func(h HandlerFunc) HandlerFunc {
+		return h.WithAuth(func(r *http.Request) bool {
+			return r.Header.Get("Authorization") == "Bearer admin"
+		})
+	})
+	admin.Get("/stats", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	})
+	router.Get("/public", func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "public")
+	})
+
+	req := httptest.NewRequest("GET", "/admin/stats", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401, got %d", w.Code)
+	}
+
+	order = nil
+	req.Header.Set("Authorization", "Bearer admin")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+	if fmt.Sprint(order) != "[root admin handler]" {
+		t.Errorf("expected [root admin handler], got %v", order)
+	}
+
+	order = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/public", nil))
+	if fmt.Sprint(order) != "[root public]" {
+		t.Errorf("expected [root public], got %v", order)
+	}
+}
+
+func TestRouter_UseAfterGroup(t *testing.T) {
+	var order []string
+	tag := func(name string) func(HandlerFunc) HandlerFunc {
+		return func(h HandlerFunc) HandlerFunc {
+			return h.Before(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+			})
+		}
+	}
+
+	router := NewRouter()
+	api := router.Group("/api", tag("api"))
+	router.Use(tag("root"))
+	api.Get("/x", func(w http.ResponseWriter, r *http.Request) {})
+	router.Get("/y", func(w http.ResponseWriter, r *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/x", nil))
+	if fmt.Sprint(order) != "[root api]" {
+		t.Errorf("expected [root api], got %v", order)
+	}
+	order = nil
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/y", nil))
+	if fmt.Sprint(order) != "[root]" {
+		t.Errorf("expected [root], got %v", order)
+	}
+}
+
+func TestRouter_URLAndRoutes(t *testing.T) {
+	router := NewRouter()
+	api := router.Group("/api/v1")
+	api.Get("/users/{id}", HandlerFunc(nil).Empty()).Name("user")
+	api.Get("/files/{path...}", HandlerFunc(nil).Empty()).Name("file")
+	router.Handle("/static/", HandlerFunc(nil).Empty())
+
+	u, err := router.URL("user", "id", "a b")
+	if err != nil || u != "/api/v1/users/a%20b" {
+		t.Errorf("expected '/api/v1/users/a%%20b', got '%s' (%v)", u, err)
+	}
+	u, err = router.URL("file", "path", "docs/read me.txt")
+	if err != nil || u != "/api/v1/files/docs/read%20me.txt" {
+		t.Errorf("expected '/api/v1/files/docs/read%%20me.txt', got '%s' (%v)", u, err)
+	}
+	if _, err := router.URL("user"); err == nil {
+		t.Error("expected error for missing parameter")
+	}
+	if _, err := router.URL("nope"); err == nil {
+		t.Error("expected error for unknown route")
+	}
+
+	routes := router.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("expected 3 routes, got %d", len(routes))
+	}
+	if routes[0] != (RouteInfo{Method: "GET", Pattern: "/api/v1/users/{id}", Name: "user"}) {
+		t.Errorf("unexpected route info: %+v", routes[0])
+	}
+	if routes[2] != (RouteInfo{Pattern: "/static/"}) {
+		t.Errorf("unexpected route info: %+v", routes[2])
+	}
+}
@@ -0,0 +1,119 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// ============================================================================
+// Security Headers and CSRF Tests
+// ============================================================================
+
+func TestHandlerFunc_WithSecureHeaders(t *testing.T) {
+	var nonce string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nonce = CSPNonceFromContext(r.Context())
+	}).WithSecureHeaders(DefaultSecurityPolicy())
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if nonce == "" {
+		t.Fatal("expected a CSP nonce in the context")
+	}
+	if csp := w.Header().Get("Content-Security-Policy"); !strings.Contains(csp, "'nonce-"+nonce+"'") {
+		t.Errorf("expected nonce in CSP, got '%s'", csp)
+	}
+	expected := map[string]string{
+		"Strict-Transport-Security": "max-age=31536000; includeSubDomains",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"X-Frame-Options":           "DENY",
+	}
+	for name, want := range expected {
+		if got := w.Header().Get(name); got != want {
+			t.Errorf("expected %s '%s', got '%s'", name, want, got)
+		}
+	}
+
+	first := nonce
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	if nonce == first {
+		t.Error("expected a new nonce per request")
+	}
+}
+
+func TestHandlerFunc_WithSecureHeaders_ZeroPolicy(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}).
+		WithSecureHeaders(SecurityPolicy{ContentSecurityPolicy: "default-src 'self'", CSPReportOnly: true})
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Header().Get("Content-Security-Policy-Report-Only") != "default-src 'self'" {
+		t.Error("expected report-only CSP")
+	}
+	if w.Header().Get("Strict-Transport-Security") != "" || w.Header().Get("X-Frame-Options") != "" {
+		t.Error("expected unset fields to leave headers unset")
+	}
+}
+
+func TestHandlerFunc_WithCSRF(t *testing.T) {
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(CSRFTokenFromContext(r.Context())))
+	}).WithCSRF(CSRFOptions{Exempt: []string{"/webhooks/"}})
+
+	// A safe request issues the token cookie.
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Value != w.Body.String() {
+		t.Fatalf("expected token cookie matching context token, got %v", cookies)
+	}
+	cookie := cookies[0]
+
+	form := url.Values{"csrf_token": {cookie.Value}}.Encode()
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		cookie   bool
+		header   string
+		fetch    string
+		expected int
+	}{
+		{"missing token", "/", "", true, "", "", http.StatusForbidden},
+		{"header token", "/", "", true, cookie.Value, "", http.StatusOK},
+		{"form token", "/", form, true, "", "", http.StatusOK},
+		{"wrong token", "/", "", true, "wrong", "", http.StatusForbidden},
+		{"no cookie", "/", "", false, cookie.Value, "", http.StatusForbidden},
+		{"same-origin fetch", "/", "", false, "", "same-origin", http.StatusOK},
+		{"cross-site fetch", "/", "", true, cookie.Value, "cross-site", http.StatusForbidden},
+		{"exempt path", "/webhooks/stripe", "", false, "", "cross-site", http.StatusOK},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+		if tt.body != "" {
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		}
+		if tt.cookie {
+			req.AddCookie(cookie)
+		}
+		if tt.header != "" {
+			req.Header.Set("X-CSRF-Token", tt.header)
+		}
+		if tt.fetch != "" {
+			req.Header.Set("Sec-Fetch-Site", tt.fetch)
+		}
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != tt.expected {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.expected, w.Code)
+		}
+	}
+}
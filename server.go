@@ -0,0 +1,209 @@
+package purefunccore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// ============================================================================
+// Server Lifecycle
+// ============================================================================
+
+// ServerConfig configures NewServer.
+type ServerConfig struct {
+	// Addr is the TCP address to listen on. Defaults to ":8080".
+	Addr string
+	// ShutdownTimeout bounds how long in-flight requests may drain.
+	// Defaults to 30 seconds.
+	ShutdownTimeout time.Duration
+	// DrainDelay keeps accepting requests after readiness starts failing,
+	// giving load balancers time to stop routing to this instance.
+	DrainDelay time.Duration
+	// ReadHeaderTimeout defaults to 10 seconds.
+	ReadHeaderTimeout time.Duration
+	// IdleTimeout defaults to 2 minutes.
+	IdleTimeout time.Duration
+	// Signals trigger shutdown. Defaults to SIGINT and SIGTERM.
+	Signals []os.Signal
+	// Logger receives lifecycle messages. Defaults to discarding them.
+	Logger func(string)
+}
+
+// Server runs a HandlerFunc with graceful shutdown.
+type Server struct {
+	cfg      ServerConfig
+	srv      *http.Server
+	draining atomic.Bool
+
+	mu    sync.Mutex
+	hooks CloseFunc
+}
+
+// NewServer creates a server for handler.
+//
+// Example:
+//
+//	srv := NewServer(router.ServeHTTP, ServerConfig{Addr: ":8080"})
+//	srv.OnShutdown(db.Close, flushMetrics)
+//	router.Get("/livez", srv.Live())
+//	router.Get("/readyz", srv.Ready())
+//	if err := srv.ListenAndServe(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func NewServer(handler HandlerFunc, cfg ServerConfig) *Server {
+	if cfg.Addr == "" {
+		cfg.Addr = ":8080"
+	}
+	if cfg.ShutdownTimeout <= 0 {
+		cfg.ShutdownTimeout = 30 * time.Second
+	}
+	if cfg.ReadHeaderTimeout <= 0 {
+		cfg.ReadHeaderTimeout = 10 * time.Second
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = 2 * time.Minute
+	}
+	if cfg.Signals == nil {
+		cfg.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = func(string) {}
+	}
+	s := &Server{cfg: cfg}
+	s.hooks = s.hooks.Empty()
+	s.srv = &http.Server{
+		Addr:              cfg.Addr,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+	return s
+}
+
+// OnShutdown registers hooks to run after requests have drained. Hooks run
+// in reverse registration order, so resources are released in the opposite
+// order they were acquired, and every hook runs even if an earlier one fails.
+func (s *Server) OnShutdown(hooks ...CloseFunc) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, hook := range hooks {
+		s.hooks = hook.Compose(s.hooks)
+	}
+}
+
+// Draining reports whether shutdown has begun.
+func (s *Server) Draining() bool {
+	return s.draining.Load()
+}
+
+// Live returns a liveness handler that replies 200 until shutdown begins,
+// then 503.
+func (s *Server) Live() HandlerFunc {
+	return s.probe("ok")
+}
+
+// Ready returns a readiness handler that replies 200 until shutdown begins,
+// then 503, so load balancers stop routing new requests.
+func (s *Server) Ready() HandlerFunc {
+	return s.probe("ready")
+}
+
+// HealthCheck returns a critical, readiness-only check that fails once
+// shutdown begins. Register it when a HealthChecker serves /readyz, so
+// dependency checks and draining share one endpoint; Server.Ready is
+// enough when there are no dependencies to check.
+//
+// Example:
+//
+//	health.Register(srv.HealthCheck())
+//	router.Get("/readyz", health.Ready())
+func (s *Server) HealthCheck() HealthCheck {
+	return HealthCheck{
+		Name:     "server",
+		Critical: true,
+		Check: func(context.Context) error {
+			if s.Draining() {
+				return errors.New("draining")
+			}
+			return nil
+		},
+	}
+}
+
+func (s *Server) probe(status string) HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Cache-Control", "no-store")
+		if s.Draining() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte("draining\n"))
+			return
+		}
+		_, _ = w.Write([]byte(status + "\n"))
+	}
+}
+
+// ListenAndServe listens on the configured address and serves until ctx is
+// cancelled or a shutdown signal arrives, then shuts down gracefully.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	ln, err := net.Listen("tcp", s.cfg.Addr)
+	if err != nil {
+		return err
+	}
+	return s.Serve(ctx, ln)
+}
+
+// Serve accepts connections on ln until ctx is cancelled or a shutdown
+// signal arrives. It then fails readiness, waits DrainDelay, stops
+// accepting, drains in-flight requests for up to ShutdownTimeout, closes
+// any that remain and runs the shutdown hooks. The returned error joins
+// serve, drain and hook failures.
+func (s *Server) Serve(ctx context.Context, ln net.Listener) error {
+	ctx, stop := signal.NotifyContext(ctx, s.cfg.Signals...)
+	defer stop()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		s.cfg.Logger(fmt.Sprintf("Listening on %s", ln.Addr()))
+		serveErr <- s.srv.Serve(ln)
+	}()
+
+	var errs []error
+	select {
+	case err := <-serveErr:
+		// The listener failed before shutdown was requested.
+		s.draining.Store(true)
+		errs = append(errs, err)
+	case <-ctx.Done():
+		stop()
+		s.cfg.Logger("Shutting down")
+		s.draining.Store(true)
+		time.Sleep(s.cfg.DrainDelay)
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), s.cfg.ShutdownTimeout)
+		defer cancel()
+		if err := s.srv.Shutdown(shutdownCtx); err != nil {
+			s.cfg.Logger("Drain deadline exceeded, closing remaining connections")
+			errs = append(errs, err, s.srv.Close())
+		}
+		if err := <-serveErr; !errors.Is(err, http.ErrServerClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	s.mu.Lock()
+	hooks := s.hooks
+	s.mu.Unlock()
+	errs = append(errs, hooks())
+	s.cfg.Logger("Shutdown complete")
+	return errors.Join(errs...)
+}
@@ -0,0 +1,155 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Server Lifecycle Tests
+// ============================================================================
+
+func TestServer_GracefulShutdown(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	srv := NewServer(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+		io.WriteString(w, "finished")
+	}, ServerConfig{DrainDelay: 20 * time.Millisecond})
+
+	var order []string
+	srv.OnShutdown(
+		func() error { order = append(order, "db"); return nil },
+		func() error { order = append(order, "cache"); return errors.New("cache flush failed") },
+	)
+	srv.OnShutdown(func() error { order = append(order, "metrics"); return nil })
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	body := make(chan string, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err != nil {
+			body <- err.Error()
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		body <- string(data)
+	}()
+	<-started
+	cancel()
+
+	// Readiness fails as soon as shutdown begins.
+	deadline := time.Now().Add(time.Second)
+	for !srv.Draining() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	w := httptest.NewRecorder()
+	srv.Ready().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected readiness 503 while draining, got %d", w.Code)
+	}
+	close(release)
+
+	if got := <-body; got != "finished" {
+		t.Errorf("expected in-flight request to finish, got '%s'", got)
+	}
+	err = <-done
+	if err == nil || err.Error() != "cache flush failed" {
+		t.Errorf("expected hook error, got %v", err)
+	}
+	if len(order) != 3 || order[0] != "metrics" || order[1] != "cache" || order[2] != "db" {
+		t.Errorf("expected hooks in reverse order, got %v", order)
+	}
+}
+
+func TestServer_DrainDeadline(t *testing.T) {
+	srv := NewServer(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}, ServerConfig{ShutdownTimeout: 20 * time.Millisecond})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- srv.Serve(ctx, ln) }()
+
+	go http.Get("http://" + ln.Addr().String())
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("expected drain deadline error, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected shutdown to give up after the deadline")
+	}
+}
+
+func TestServer_Probes(t *testing.T) {
+	srv := NewServer(func(w http.ResponseWriter, r *http.Request) {}, ServerConfig{})
+	for _, probe := range []HandlerFunc{srv.Live(), srv.Ready()} {
+		w := httptest.NewRecorder()
+		probe.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+		if w.Code != http.StatusOK {
+			t.Errorf("expected 200 before shutdown, got %d", w.Code)
+		}
+	}
+}
+
+func TestServer_HealthCheck(t *testing.T) {
+	srv := NewServer(func(w http.ResponseWriter, r *http.Request) {}, ServerConfig{})
+	health := NewHealthChecker(HealthOptions{})
+	health.Register(srv.HealthCheck())
+
+	ready := func() int {
+		w := httptest.NewRecorder()
+		health.Ready().ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		return w.Code
+	}
+	if code := ready(); code != http.StatusOK {
+		t.Errorf("expected 200 before shutdown, got %d", code)
+	}
+	srv.draining.Store(true)
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 while draining, got %d", code)
+	}
+	w := httptest.NewRecorder()
+	health.Live().ServeHTTP(w, httptest.NewRequest("GET", "/livez", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected liveness unaffected by draining, got %d", w.Code)
+	}
+}
+
+func TestCloseFunc_Compose(t *testing.T) {
+	var calls []string
+	first := CloseFunc(func() error { calls = append(calls, "first"); return errors.New("first failed") })
+	second := CloseFunc(func() error { calls = append(calls, "second"); return nil })
+
+	err := first.Compose(second).Compose(first.Empty())()
+	if len(calls) != 2 || calls[1] != "second" {
+		t.Errorf("expected both closers to run, got %v", calls)
+	}
+	if err == nil || err.Error() != "first failed" {
+		t.Errorf("expected joined error, got %v", err)
+	}
+}
@@ -0,0 +1,173 @@
+package purefunccore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ============================================================================
+// Server-Sent Events
+// ============================================================================
+
+// Event is a single server-sent event. Data may span several lines.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	// Retry tells the client how long to wait before reconnecting.
+	Retry time.Duration
+}
+
+// EventStream writes server-sent events to a client. Its methods are safe
+// for concurrent use and flush after every write.
+type EventStream struct {
+	ctx         context.Context
+	mu          sync.Mutex
+	w           http.ResponseWriter
+	rc          *http.ResponseController
+	lastEventID string
+}
+
+// LastEventID returns the Last-Event-ID sent by a reconnecting client, so
+// the stream can resume after the last event it received.
+func (s *EventStream) LastEventID() string {
+	return s.lastEventID
+}
+
+// Send writes an event. It fails with the context error once the client
+// has disconnected, and with http.ErrNotSupported if the response writer
+// cannot flush.
+func (s *EventStream) Send(ev Event) error {
+	var b strings.Builder
+	if ev.ID != "" {
+		b.WriteString("id: " + sseField(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		b.WriteString("event: " + sseField(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		b.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+	for _, line := range strings.Split(strings.ReplaceAll(ev.Data, "\r\n", "\n"), "\n") {
+		b.WriteString("data: " + line + "\n")
+	}
+	b.WriteString("\n")
+	return s.write(b.String())
+}
+
+// SendJSON sends v encoded as JSON in the data field.
+func (s *EventStream) SendJSON(event string, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return s.Send(Event{Event: event, Data: string(data)})
+}
+
+// Comment writes a comment line, which clients ignore. It is used for
+// heartbeats that keep proxies from closing idle connections.
+func (s *EventStream) Comment(text string) error {
+	return s.write(": " + sseField(text) + "\n\n")
+}
+
+func (s *EventStream) write(msg string) error {
+	if err := s.ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, err := s.w.Write([]byte(msg)); err != nil {
+		return err
+	}
+	return s.rc.Flush()
+}
+
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+// SSEOptions configures SSEHandlerWith.
+type SSEOptions struct {
+	// Heartbeat is the interval between keep-alive comments.
+	// Defaults to 15 seconds; negative disables heartbeats.
+	Heartbeat time.Duration
+	// Retry, if set, is sent first as the client reconnection delay.
+	Retry time.Duration
+}
+
+// SSEHandler adapts fn into a text/event-stream endpoint. fn runs until it
+// returns or the client disconnects, which cancels ctx. An error returned
+// while the client is still connected is sent as a final "error" event
+// holding the ProblemFor details, so 5xx causes are not exposed.
+//
+// Flushing passes through WithTimeout, WithLogging, WithCompression and the
+// other decorators, but a WithTimeout deadline still ends the stream.
+//
+// Example:
+//
+//	handler := SSEHandler(func(ctx context.Context, s *EventStream) error {
+//	    for update := range prices(ctx, s.LastEventID()) {
+//	        if err := s.Send(Event{ID: update.ID, Data: update.JSON}); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return nil
+//	})
+func SSEHandler(fn func(context.Context, *EventStream) error) HandlerFunc {
+	return SSEHandlerWith(SSEOptions{}, fn)
+}
+
+// SSEHandlerWith is SSEHandler with explicit options.
+func SSEHandlerWith(opts SSEOptions, fn func(context.Context, *EventStream) error) HandlerFunc {
+	if opts.Heartbeat == 0 {
+		opts.Heartbeat = 15 * time.Second
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		h.Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		_ = rc.Flush()
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		stream := &EventStream{ctx: ctx, w: w, rc: rc, lastEventID: r.Header.Get("Last-Event-ID")}
+		if opts.Retry > 0 {
+			_ = stream.write("retry: " + strconv.FormatInt(opts.Retry.Milliseconds(), 10) + "\n\n")
+		}
+
+		var wg sync.WaitGroup
+		if opts.Heartbeat > 0 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				ticker := time.NewTicker(opts.Heartbeat)
+				defer ticker.Stop()
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						if stream.Comment("heartbeat") != nil {
+							return
+						}
+					}
+				}
+			}()
+		}
+
+		err := fn(ctx, stream)
+		if err != nil && ctx.Err() == nil {
+			_ = stream.SendJSON("error", ProblemFor(err))
+		}
+		cancel()
+		wg.Wait()
+	}
+}
@@ -0,0 +1,126 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// ============================================================================
+// Server-Sent Events Tests
+// ============================================================================
+
+func TestSSEHandler_StreamsThroughMiddleware(t *testing.T) {
+	next := make(chan struct{})
+	handler := SSEHandlerWith(SSEOptions{Retry: 2 * time.Second}, func(ctx context.Context, s *EventStream) error {
+		s.Send(Event{ID: "1", Event: "tick", Data: "line one\nline two"})
+		<-next
+		return s.Send(Event{ID: "2", Data: "done"})
+	}).WithLogging(func(string) {}).WithTimeout(5 * time.Second)
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("expected text/event-stream, got '%s'", ct)
+	}
+
+	// The first event must arrive before the handler finishes.
+	reader := bufio.NewReader(resp.Body)
+	var got []string
+	for len(got) < 7 {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("unexpected read error: %v", err)
+		}
+		got = append(got, strings.TrimSuffix(line, "\n"))
+	}
+	close(next)
+
+	expected := []string{"retry: 2000", "", "id: 1", "event: tick", "data: line one", "data: line two", ""}
+	if strings.Join(got, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %q, got %q", expected, got)
+	}
+}
+
+func TestSSEHandler_LastEventIDAndHeartbeat(t *testing.T) {
+	handler := SSEHandlerWith(SSEOptions{Heartbeat: 5 * time.Millisecond}, func(ctx context.Context, s *EventStream) error {
+		time.Sleep(20 * time.Millisecond)
+		return s.Send(Event{Data: "resume after " + s.LastEventID()})
+	})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("Last-Event-ID", "41")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if !strings.Contains(body, ": heartbeat\n\n") {
+		t.Errorf("expected heartbeat comments, got %q", body)
+	}
+	if !strings.HasSuffix(body, "data: resume after 41\n\n") {
+		t.Errorf("expected resumed event, got %q", body)
+	}
+}
+
+func TestSSEHandler_ClientDisconnect(t *testing.T) {
+	stopped := make(chan error, 1)
+	handler := SSEHandler(func(ctx context.Context, s *EventStream) error {
+		for {
+			if err := s.Send(Event{Data: "tick"}); err != nil {
+				stopped <- err
+				return err
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	req, _ := http.NewRequestWithContext(ctx, "GET", server.URL, nil)
+	resp, err := server.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	bufio.NewReader(resp.Body).ReadString('\n')
+	cancel()
+	resp.Body.Close()
+
+	select {
+	case err := <-stopped:
+		if err == nil {
+			t.Error("expected an error after disconnect")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected handler to stop after client disconnect")
+	}
+}
+
+func TestSSEHandler_ErrorEvent(t *testing.T) {
+	handler := SSEHandler(func(ctx context.Context, s *EventStream) error {
+		return errors.New("database password is hunter2")
+	})
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	body := w.Body.String()
+	if !strings.Contains(body, "event: error\n") || !strings.Contains(body, `"status":500`) {
+		t.Errorf("expected problem error event, got %q", body)
+	}
+	if strings.Contains(body, "hunter2") {
+		t.Error("expected internal error details to be hidden")
+	}
+}
@@ -0,0 +1,225 @@
+package purefunccore
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ============================================================================
+// Request ID and Trace Context
+// ============================================================================
+
+// RequestIDOptions configures HandlerFunc.WithRequestID.
+type RequestIDOptions struct {
+	// Header carries the request ID. Defaults to X-Request-ID.
+	Header string
+	// Generate creates new IDs. Defaults to 16 random bytes in hex.
+	Generate func() string
+	// IgnoreIncoming always generates a new ID instead of trusting the
+	// client's. Incoming IDs longer than 128 characters or containing
+	// non-printable characters are always replaced.
+	IgnoreIncoming bool
+}
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID.
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// WithRequestID accepts or generates a request ID, stores it in the request
+// context and echoes it in the response header.
+//
+// Example:
+//
+//	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+//	    log.Printf("[%s] handling", RequestIDFromContext(r.Context()))
+//	}).WithRequestID(RequestIDOptions{})
+func (f HandlerFunc) WithRequestID(opts RequestIDOptions) HandlerFunc {
+	header := opts.Header
+	if header == "" {
+		header = "X-Request-ID"
+	}
+	generate := opts.Generate
+	if generate == nil {
+		generate = func() string { return randomHex(16) }
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(header)
+		if opts.IgnoreIncoming || !validRequestID(id) {
+			id = generate()
+		}
+		w.Header().Set(header, id)
+		f(w, r.WithContext(ContextWithRequestID(r.Context(), id)))
+	}
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ErrInvalidTraceParent is returned for malformed traceparent headers.
+var ErrInvalidTraceParent = errors.New("invalid traceparent")
+
+// TraceContext is a W3C Trace Context (traceparent and tracestate).
+type TraceContext struct {
+	TraceID  [16]byte
+	SpanID   [8]byte // the current span
+	ParentID [8]byte // the caller's span, zero for a new trace
+	Flags    byte
+	State    string // raw tracestate header
+}
+
+// Sampled reports whether the sampled flag is set.
+func (tc TraceContext) Sampled() bool {
+	return tc.Flags&0x01 != 0
+}
+
+// TraceParent formats the traceparent header for the current span.
+func (tc TraceContext) TraceParent() string {
+	return "00-" + hex.EncodeToString(tc.TraceID[:]) + "-" + hex.EncodeToString(tc.SpanID[:]) + "-" + hex.EncodeToString([]byte{tc.Flags})
+}
+
+// Child returns a trace context for a new span whose parent is tc's span.
+func (tc TraceContext) Child() TraceContext {
+	child := tc
+	child.ParentID = tc.SpanID
+	child.SpanID = newSpanID()
+	return child
+}
+
+// ParseTraceParent parses a traceparent header. The returned context's
+// SpanID is the caller's span.
+func ParseTraceParent(s string) (TraceContext, error) {
+	var tc TraceContext
+	parts := strings.Split(strings.TrimSpace(s), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return tc, ErrInvalidTraceParent
+	}
+	// Version 00 has exactly four fields; future versions may append more.
+	if parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return tc, ErrInvalidTraceParent
+	}
+	if strings.ToLower(s) != s {
+		return tc, ErrInvalidTraceParent
+	}
+	var flags [1]byte
+	if _, err := hex.Decode(flags[:], []byte(parts[3])); err != nil {
+		return tc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(tc.TraceID[:], []byte(parts[1])); err != nil || tc.TraceID == [16]byte{} {
+		return tc, ErrInvalidTraceParent
+	}
+	if _, err := hex.Decode(tc.SpanID[:], []byte(parts[2])); err != nil || tc.SpanID == [8]byte{} {
+		return tc, ErrInvalidTraceParent
+	}
+	tc.Flags = flags[0]
+	return tc, nil
+}
+
+// NewTraceContext starts a new sampled trace.
+func NewTraceContext() TraceContext {
+	var tc TraceContext
+	for tc.TraceID == [16]byte{} {
+		_, _ = rand.Read(tc.TraceID[:])
+	}
+	tc.SpanID = newSpanID()
+	tc.Flags = 0x01
+	return tc
+}
+
+func newSpanID() [8]byte {
+	var id [8]byte
+	for id == [8]byte{} {
+		_, _ = rand.Read(id[:])
+	}
+	return id
+}
+
+type traceContextKey struct{}
+
+// ContextWithTraceContext returns a copy of ctx carrying tc.
+func ContextWithTraceContext(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceContextFromContext returns the trace context stored in ctx, if any.
+func TraceContextFromContext(ctx context.Context) (TraceContext, bool) {
+	tc, ok := ctx.Value(traceContextKey{}).(TraceContext)
+	return tc, ok
+}
+
+// WithTraceContext continues the trace from the incoming traceparent and
+// tracestate headers, or starts a new one, and stores a child span for this
+// request in the context.
+func (f HandlerFunc) WithTraceContext() HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tc, err := ParseTraceParent(r.Header.Get("traceparent"))
+		if err == nil {
+			tc.State = strings.Join(r.Header.Values("tracestate"), ",")
+			tc = tc.Child()
+		} else {
+			tc = NewTraceContext()
+		}
+		f(w, r.WithContext(ContextWithTraceContext(r.Context(), tc)))
+	}
+}
+
+// PropagateTrace forwards the trace context and request ID from the request
+// context as traceparent, tracestate and X-Request-ID headers. Each outbound
+// call gets its own child span ID.
+//
+// Example:
+//
+//	client := &http.Client{
+//	    Transport: RoundTripperFunc(http.DefaultTransport.RoundTrip).PropagateTrace(),
+//	}
+func (f RoundTripperFunc) PropagateTrace() RoundTripperFunc {
+	return func(req *http.Request) (*http.Response, error) {
+		tc, hasTrace := TraceContextFromContext(req.Context())
+		id := RequestIDFromContext(req.Context())
+		if !hasTrace && id == "" {
+			return f(req)
+		}
+
+		req = req.Clone(req.Context())
+		if hasTrace {
+			out := tc.Child()
+			req.Header.Set("traceparent", out.TraceParent())
+			if out.State != "" {
+				req.Header.Set("tracestate", out.State)
+			} else {
+				req.Header.Del("tracestate")
+			}
+		}
+		if id != "" && req.Header.Get("X-Request-ID") == "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		return f(req)
+	}
+}
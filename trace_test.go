@@ -0,0 +1,126 @@
+// nolint:errcheck
+package purefunccore
+
+import (
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// ============================================================================
+// Request ID and Trace Context Tests
+// ============================================================================
+
+func TestHandlerFunc_WithRequestID(t *testing.T) {
+	var seen string
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}).WithRequestID(RequestIDOptions{Generate: func() string { return "generated" }})
+
+	tests := []struct {
+		incoming, want string
+	}{
+		{"", "generated"},
+		{"abc-123", "abc-123"},
+		{"bad id\n", "generated"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("X-Request-ID", tt.incoming)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if seen != tt.want {
+			t.Errorf("incoming %q: expected context ID '%s', got '%s'", tt.incoming, tt.want, seen)
+		}
+		if got := w.Header().Get("X-Request-ID"); got != tt.want {
+			t.Errorf("incoming %q: expected echoed ID '%s', got '%s'", tt.incoming, tt.want, got)
+		}
+	}
+}
+
+func TestParseTraceParent(t *testing.T) {
+	tc, err := ParseTraceParent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if err != nil {
+		t.Fatalf("expected valid traceparent, got %v", err)
+	}
+	if hex.EncodeToString(tc.TraceID[:]) != "4bf92f3577b34da6a3ce929d0e0e4736" || !tc.Sampled() {
+		t.Errorf("unexpected trace context: %+v", tc)
+	}
+	if tc.TraceParent() != "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01" {
+		t.Errorf("expected round trip, got '%s'", tc.TraceParent())
+	}
+
+	invalid := []string{
+		"",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+	}
+	for _, s := range invalid {
+		if _, err := ParseTraceParent(s); err == nil {
+			t.Errorf("expected %q to be rejected", s)
+		}
+	}
+}
+
+func TestTraceContext_PropagatesThroughTransport(t *testing.T) {
+	var outbound *http.Request
+	transport := RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		outbound = req
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}).PropagateTrace()
+
+	var current TraceContext
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current, _ = TraceContextFromContext(r.Context())
+		req, _ := http.NewRequestWithContext(r.Context(), "GET", "http://backend/", nil)
+		transport.RoundTrip(req)
+	}).WithTraceContext().WithRequestID(RequestIDOptions{})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	req.Header.Set("tracestate", "vendor=abc")
+	req.Header.Set("X-Request-ID", "req-1")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if hex.EncodeToString(current.ParentID[:]) != "00f067aa0ba902b7" {
+		t.Errorf("expected parent span from header, got %x", current.ParentID)
+	}
+	if current.SpanID == current.ParentID {
+		t.Error("expected a new span ID for the request")
+	}
+
+	out, err := ParseTraceParent(outbound.Header.Get("traceparent"))
+	if err != nil {
+		t.Fatalf("expected outbound traceparent, got %v", err)
+	}
+	if out.TraceID != current.TraceID || out.SpanID == current.SpanID {
+		t.Errorf("expected same trace with a new span, got %s", outbound.Header.Get("traceparent"))
+	}
+	if outbound.Header.Get("tracestate") != "vendor=abc" {
+		t.Errorf("expected tracestate forwarded, got '%s'", outbound.Header.Get("tracestate"))
+	}
+	if outbound.Header.Get("X-Request-ID") != "req-1" {
+		t.Errorf("expected request ID forwarded, got '%s'", outbound.Header.Get("X-Request-ID"))
+	}
+}
+
+func TestHandlerFunc_WithTraceContext_StartsNewTrace(t *testing.T) {
+	var tc TraceContext
+	var ok bool
+	handler := HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tc, ok = TraceContextFromContext(r.Context())
+	}).WithTraceContext()
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("traceparent", "garbage")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !ok || tc.TraceID == [16]byte{} || tc.ParentID != [8]byte{} {
+		t.Errorf("expected a fresh root trace, got %+v", tc)
+	}
+}